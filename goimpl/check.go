@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ajmesa9891/impl/impl"
+)
+
+func logFatalCheckUsage(args []string) {
+	log.Fatalf("Must pass exactly 2 arguments to check:\n"+
+		"  (1) interface path (e.g., sort.Interface)\n"+
+		"  (2) the type to check (e.g., '*Receiver')\n"+
+		"optionally preceded by -strict to fail on constructs that cannot be handled,\n"+
+		"but got %d arguments: %q.\n"+
+		"visit https://github.com/ajmesa9891/impl for more details.", len(args), args)
+}
+
+// checkMain reports why a type, declared in the package in the current
+// directory, does not implement an interface. It exits with status 1 if the
+// type does not implement it.
+func checkMain(args []string) {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	flags.BoolVar(strict, "strict", false, strictUsage)
+	flags.Parse(args)
+	args = flags.Args()
+	if len(args) < 2 {
+		logFatalCheckUsage(args)
+	}
+
+	interfacePath, typeArgs := splitInterfacePath(args)
+	if len(typeArgs) != 1 {
+		logFatalCheckUsage(args)
+	}
+	typeName := typeArgs[0]
+	opts := impl.Options{PkgPath: importPath("."), Dir: ".", Strict: *strict}
+	result, err := impl.Check(interfacePath, typeName, opts)
+	if err != nil {
+		log.Fatalf("could not check type %q against interface path %q: %s\n", typeName, interfacePath, err)
+	}
+	for _, warning := range result.Warnings {
+		log.Printf("warning: %s\n", warning)
+	}
+	for _, problem := range result.Problems {
+		fmt.Println(problem)
+	}
+	if len(result.Problems) > 0 {
+		os.Exit(1)
+	}
+}
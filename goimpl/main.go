@@ -2,15 +2,22 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/ajmesa9891/impl/impl"
+	"golang.org/x/tools/go/ast/astutil"
 )
 
 var commandPattern = regexp.MustCompile(`//go:generate\s*goimp\s*(.*)\n`)
@@ -19,12 +26,43 @@ const (
 	cmdName = "goimp"
 )
 
+const strictUsage = "fail instead of writing scaffolding for interfaces with constructs that cannot be handled"
+
+const modeUsage = `what to write for the interface:
+  stub      methods for the receiver that panic, to be implemented
+  fake      the receiver's type, with a func field per method that the method calls
+  mock      the receiver's type, as a mock told the calls it expects
+  spy       the receiver's type, recording the calls made to it and passing them on
+  recorder  the receiver's type, recording the calls made to it to a golden file or replaying them
+  wrapper   the receiver's type, passing the calls made to it on to an inner implementation
+  logging   the receiver's type, logging the calls made to it with log/slog and passing them on
+  metrics   the receiver's type, recording the calls made to it, their latency and errors, and passing them on
+  timeout   the receiver's type, passing the calls made to it on with a timeout per method applied to their context
+  retry     the receiver's type, passing the calls made to it on and attempting again those that fail
+  mutex     the receiver's type, passing the calls made to it on one at a time
+  multi     the receiver's type, passing the calls made to it on to each of several implementations
+  fallback  the receiver's type, passing the calls made to it on to a primary implementation, then to secondaries while they fail`
+
+var (
+	strict  = flag.Bool("strict", false, strictUsage)
+	missing = flag.Bool("missing", false,
+		"only write scaffolding for the methods the receiver's type does not have yet")
+	mode     = flag.String("mode", impl.Stub.String(), modeUsage)
+	readOnly = flag.String("readonly", "",
+		"the comma-separated read-only methods (e.g., Get,Len), passed on at the same time by -mode=mutex")
+)
+
 func logFatalUsage(args []string) {
 	log.Fatalf("Must pass exactly 3 arguments:\n"+
 		"  (1) the file name (perhaps $GOFILE if using go:generate)\n"+
 		"  (2) interface path (e.g., sort.Interface)\n"+
 		"  (3) the receiver (e.g., 'r *Receiver')\n"+
+		"optionally preceded by -strict to fail on constructs that cannot be handled,\n"+
+		"by -missing to only scaffold the methods the receiver's type does not have,\n"+
+		"and by -mode to write something else than stubs (e.g., -mode=fake),\n"+
 		"but got %d arguments: %q.\n"+
+		"Run \"goimpl sync\" to update existing implementations of an interface instead,\n"+
+		"or \"goimpl check\" to find out why a type does not implement it.\n"+
 		"visit https://github.com/ajmesa9891/impl for more details.", len(args), args)
 }
 
@@ -32,29 +70,131 @@ func main() {
 	log.SetFlags(0)
 	log.SetPrefix("impl: ")
 
-	args := os.Args[1:]
+	if len(os.Args) > 1 && os.Args[1] == "sync" {
+		syncMain(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		checkMain(os.Args[2:])
+		return
+	}
+
+	flag.Parse()
+	args := flag.Args()
 	if len(args) < 3 {
 		logFatalUsage(args)
 	}
 
 	file := filepath.Join(".", args[0])
-	interfacePath := args[1]
-	receiver := strings.Replace(strings.Join(args[2:], " "), "'", "", -1)
+	interfacePath, receiverArgs := splitInterfacePath(args[1:])
+	if len(receiverArgs) < 1 {
+		logFatalUsage(args)
+	}
+	receiver := strings.Replace(strings.Join(receiverArgs, " "), "'", "", -1)
 	var w bytes.Buffer
 
-	err := impl.Impl(interfacePath, receiver, &w)
+	imports, err := fileImports(file)
+	if err != nil {
+		log.Fatalf("could not read the imports of file %q: %s\n", file, err)
+	}
+	m, err := impl.ParseMode(*mode)
 	if err != nil {
-		log.Fatalf("could not build scaffolding for interface path %q: %s\n%",
+		log.Fatalf("%s\n", err)
+	}
+	dir := filepath.Dir(file)
+	opts := impl.Options{PkgPath: importPath(dir), Imports: imports, Dir: dir, Missing: *missing, Strict: *strict, Mode: m}
+	if *readOnly != "" {
+		opts.ReadOnly = strings.Split(*readOnly, ",")
+	}
+	result, err := impl.ImplWithOptions(interfacePath, receiver, &w, opts)
+	if err != nil {
+		log.Fatalf("could not build scaffolding for interface path %q: %s\n",
 			interfacePath, err)
 	}
-	err = writeInterfaceScaffolding(file, interfacePath, w.String())
+	for _, warning := range result.Warnings {
+		log.Printf("warning: %s\n", warning)
+	}
+	for _, mismatch := range result.Mismatches {
+		log.Printf("%s: method %s has type %s, but the interface wants %s\n",
+			mismatch.Pos, mismatch.Method.Name, mismatch.Have, mismatch.Want)
+	}
+	err = writeInterfaceScaffolding(file, interfacePath, w.String(), result.Imports)
 	if err != nil {
 		log.Fatalf("could not write scaffolding to file: %v\nscaffolding:\n%s", err, w.String())
 	}
 	log.Printf("wrote interface scaffolding for %q in file %q\n", interfacePath, file)
 }
 
-func writeInterfaceScaffolding(inputPath, interfacePath, scaffolding string) error {
+// splitInterfacePath splits the interface path from the arguments that
+// follow it. Type arguments of the interface path may have been split into
+// several arguments (e.g., "cache.Store[string," and "int]").
+func splitInterfacePath(args []string) (string, []string) {
+	depth := 0
+	for i, arg := range args {
+		depth += strings.Count(arg, "[") - strings.Count(arg, "]")
+		if depth <= 0 {
+			return strings.Join(args[:i+1], " "), args[i+1:]
+		}
+	}
+	return strings.Join(args, " "), nil
+}
+
+// importPath returns the import path of the package in dir, as the go
+// command run in it sees it, or "" if it cannot be determined.
+func importPath(dir string) string {
+	cmd := exec.Command("go", "list", "-e", "-find", "-f", "{{.ImportPath}}", ".")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	path := strings.TrimSpace(string(out))
+	if err != nil || path == "" || strings.HasPrefix(path, "_/") { // "_/..." is outside GOPATH and modules
+		return ""
+	}
+	return path
+}
+
+// fileImports returns the imports of the Go file at path.
+func fileImports(path string) ([]impl.Import, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	imports := make([]impl.Import, 0, len(f.Imports))
+	for _, spec := range f.Imports {
+		imp := impl.Import{}
+		imp.Path, err = strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		if spec.Name != nil {
+			imp.Name = spec.Name.Name
+		}
+		imports = append(imports, imp)
+	}
+	return imports, nil
+}
+
+// addImports adds to the Go source src the imports it is missing.
+func addImports(src []byte, imports []impl.Import) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	added := false
+	for _, imp := range imports {
+		added = astutil.AddNamedImport(fset, f, imp.Name, imp.Path) || added
+	}
+	if !added {
+		return src, nil
+	}
+	var w bytes.Buffer
+	if err := format.Node(&w, fset, f); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+func writeInterfaceScaffolding(inputPath, interfacePath, scaffolding string, imports []impl.Import) error {
 	newContent := ""
 	content, err := ioutil.ReadFile(inputPath)
 	if err != nil {
@@ -69,7 +209,12 @@ func writeInterfaceScaffolding(inputPath, interfacePath, scaffolding string) err
 		}
 	}
 
-	err = ioutil.WriteFile(inputPath, []byte(newContent), 0)
+	src, err := addImports([]byte(newContent), imports)
+	if err != nil {
+		return fmt.Errorf("adding imports to file %q: %s", inputPath, err)
+	}
+
+	err = ioutil.WriteFile(inputPath, src, 0)
 	if err != nil {
 		return fmt.Errorf("writing file %q: %s", inputPath, err)
 	}
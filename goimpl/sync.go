@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/ajmesa9891/impl/impl"
+)
+
+func logFatalSyncUsage(args []string) {
+	log.Fatalf("Must pass at least 2 arguments to sync:\n"+
+		"  (1) interface path (e.g., sort.Interface)\n"+
+		"  (2) the receivers of the implementations to update (e.g., 'r *Receiver' 'o *Other')\n"+
+		"optionally preceded by -strict to fail on constructs that cannot be handled,\n"+
+		"but got %d arguments: %q.\n"+
+		"visit https://github.com/ajmesa9891/impl for more details.", len(args), args)
+}
+
+// syncMain updates the implementations of an interface by the types of the
+// given receivers, declared in the package in the current directory, after
+// the interface changed.
+func syncMain(args []string) {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	flags.BoolVar(strict, "strict", false, strictUsage)
+	flags.Parse(args)
+	args = flags.Args()
+	if len(args) < 2 {
+		logFatalSyncUsage(args)
+	}
+
+	interfacePath, receiverArgs := splitInterfacePath(args)
+	receivers := splitReceivers(receiverArgs)
+	if len(receivers) < 1 {
+		logFatalSyncUsage(args)
+	}
+	opts := impl.Options{PkgPath: importPath("."), Dir: ".", Strict: *strict}
+	for _, receiver := range receivers {
+		result, err := impl.Sync(interfacePath, receiver, opts)
+		if err != nil {
+			log.Fatalf("could not sync receiver %q with interface path %q: %s\n", receiver, interfacePath, err)
+		}
+		for _, warning := range result.Warnings {
+			log.Printf("warning: %s\n", warning)
+		}
+		for _, file := range result.Files {
+			err = ioutil.WriteFile(file.Name, file.Src, 0)
+			if err != nil {
+				log.Fatalf("could not write file %q: %s\n", file.Name, err)
+			}
+		}
+		log.Printf("synced receiver %q with %q: added %q, updated %q and flagged %q\n",
+			receiver, interfacePath, result.Added, result.Updated, result.Stale)
+	}
+}
+
+// splitReceivers splits args into receivers. Receivers that were not quoted
+// were split into their name and type, which are joined back (e.g., "ml" and
+// "*musicList"): an argument without a space is joined with the argument that
+// follows it.
+func splitReceivers(args []string) []string {
+	var receivers []string
+	for i := 0; i < len(args); i++ {
+		receiver := strings.Replace(args[i], "'", "", -1)
+		if !strings.Contains(receiver, " ") && i+1 < len(args) {
+			i++
+			receiver += " " + strings.Replace(args[i], "'", "", -1)
+		}
+		receivers = append(receivers, receiver)
+	}
+	return receivers
+}
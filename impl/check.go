@@ -0,0 +1,258 @@
+package impl
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ProblemKind classifies the reasons a type does not implement an interface.
+type ProblemKind int
+
+const (
+	// MissingMethod is a method of the interface the type does not have.
+	MissingMethod ProblemKind = iota
+	// WrongSignature is a method the type has with a different signature.
+	WrongSignature
+	// PointerReceiver is a method the type only has through a pointer, as
+	// it is declared with a pointer receiver.
+	PointerReceiver
+	// UnexportedMethod is a method that is unexported in the interface or
+	// in the type (but not in both), or that is unexported in an interface
+	// of another package.
+	UnexportedMethod
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case MissingMethod:
+		return "missing method"
+	case WrongSignature:
+		return "wrong signature"
+	case PointerReceiver:
+		return "pointer receiver"
+	case UnexportedMethod:
+		return "unexported method"
+	}
+	return fmt.Sprintf("ProblemKind(%d)", int(k))
+}
+
+// Problem is a reason a type does not implement an interface.
+type Problem struct {
+	Kind ProblemKind
+
+	// Method is the name of the interface's method.
+	Method string
+
+	// Pos is the position of the type's method, or of the type's
+	// declaration for methods it does not have.
+	Pos token.Position
+
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Pos.IsValid() {
+		return p.Pos.String() + ": " + p.Message
+	}
+	return p.Message
+}
+
+// CheckResult describes why a type does not implement an interface.
+type CheckResult struct {
+	// Problems are the reasons, in the order of the interface's methods.
+	// There are none when the type implements the interface.
+	Problems []Problem
+
+	// Warnings describe the constructs of the interface that could not be
+	// handled, so they were not checked.
+	Warnings []Warning
+}
+
+// Check compares the methods of the interface at path (see Impl) with the
+// methods of typeName, a type declared in the package opts.PkgPath or a
+// pointer to it (e.g., "File" or "*File"), and reports why it does not
+// implement the interface: the methods it is missing, the methods whose
+// signatures are different (naming the parameter or result that differs),
+// the methods it only has through a pointer, and the methods that are
+// exported in one of them but not in the other.
+func Check(path, typeName string, opts Options) (*CheckResult, error) {
+	recv, err := parseReceiver(typeName)
+	if err != nil {
+		return nil, err
+	}
+	err = recv.validate(opts.PkgPath, opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg, obj := recv.lookup(opts.PkgPath, opts.Dir)
+	if obj == nil {
+		return nil, NewInvalidReceiverError("type %q must be declared in package %q to be checked", recv.typeName, opts.PkgPath)
+	}
+	opts.Missing = false
+	iface, err := buildInterface(path, recv, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Strict && len(iface.Warnings) > 0 {
+		return nil, NewUnhandledConstructError(iface.Warnings)
+	}
+
+	c := &checker{
+		recv: recv,
+		pkg:  pkg,
+		obj:  obj,
+		q:    newQualifier(opts.PkgPath, opts.Imports),
+	}
+	result := &CheckResult{Warnings: iface.Warnings}
+	for _, m := range iface.Methods {
+		if p, ok := c.check(iface, m); ok {
+			result.Problems = append(result.Problems, p)
+		}
+	}
+	return result, nil
+}
+
+// checker checks the methods of a type against the methods of an interface.
+type checker struct {
+	recv *receiver
+	pkg  *typedPackage
+	obj  *types.TypeName
+	q    *qualifier
+}
+
+// methodPackage returns the package of the interface declaring m, or nil if
+// it is not known.
+func methodPackage(m Method) *types.Package {
+	if m.Signature == nil || m.Signature.Recv() == nil {
+		return nil
+	}
+	return m.Signature.Recv().Pkg()
+}
+
+// check returns the problem with the type's method for m, if any.
+func (c *checker) check(iface *Interface, m Method) (Problem, bool) {
+	p := Problem{Method: m.Name, Pos: c.pkg.fset.Position(c.obj.Pos())}
+	if mpkg := methodPackage(m); !token.IsExported(m.Name) && mpkg != nil && mpkg.Path() != c.pkg.ImportPath {
+		p.Kind = UnexportedMethod
+		p.Message = fmt.Sprintf("%s cannot implement %s (method %s is unexported in package %s)",
+			c.recv.typeString(), iface.Name, m.Name, mpkg.Path())
+		return p, true
+	}
+
+	obj, _, _ := types.LookupFieldOrMethod(c.obj.Type(), true, c.pkg.types, m.Name)
+	fn, isMethod := obj.(*types.Func)
+	if obj == nil {
+		if other := c.otherCase(m.Name); other != nil {
+			p.Kind = UnexportedMethod
+			p.Pos = c.pkg.fset.Position(other.Pos())
+			p.Message = fmt.Sprintf("%s does not implement %s (missing method %s: it has %s instead)",
+				c.recv.typeString(), iface.Name, m.Name, other.Name())
+			return p, true
+		}
+		p.Kind = MissingMethod
+		p.Message = fmt.Sprintf("%s does not implement %s (missing method %s)\n\twant %s%s",
+			c.recv.typeString(), iface.Name, m.Name, m.Name, strings.TrimPrefix(methodType(m), "func"))
+		return p, true
+	}
+	p.Pos = c.pkg.fset.Position(obj.Pos())
+	if !isMethod {
+		p.Kind = MissingMethod
+		p.Message = fmt.Sprintf("%s does not implement %s (%s is a field, not a method)",
+			c.recv.typeString(), iface.Name, m.Name)
+		return p, true
+	}
+
+	sig := fn.Type().(*types.Signature)
+	if m.Signature != nil && !sameSignature(m.Signature, sig) {
+		p.Kind = WrongSignature
+		p.Message = fmt.Sprintf("%s does not implement %s (wrong type for method %s: %s)\n\thave %s%s\n\twant %s%s",
+			c.recv.typeString(), iface.Name, m.Name, signatureDifference(m, sig, c.q),
+			m.Name, strings.TrimPrefix(c.q.typeString(sig), "func"), m.Name, strings.TrimPrefix(methodType(m), "func"))
+		return p, true
+	}
+	if !c.recv.pointer && types.NewMethodSet(c.obj.Type()).Lookup(c.pkg.types, m.Name) == nil {
+		p.Kind = PointerReceiver
+		p.Message = fmt.Sprintf("%s does not implement %s (method %s has pointer receiver)",
+			c.recv.typeString(), iface.Name, m.Name)
+		return p, true
+	}
+	return Problem{}, false
+}
+
+// otherCase returns the type's method that has the same name as the method
+// name but for the case of its first letter (e.g., "read" for "Read"), if
+// any.
+func (c *checker) otherCase(name string) *types.Func {
+	first, size := utf8.DecodeRuneInString(name)
+	other := unicode.ToUpper(first)
+	if unicode.IsUpper(first) {
+		other = unicode.ToLower(first)
+	}
+	if other == first {
+		return nil
+	}
+	otherName := string(other) + name[size:]
+	mset := types.NewMethodSet(types.NewPointer(c.obj.Type()))
+	for i := 0; i < mset.Len(); i++ {
+		if fn := mset.At(i).Obj(); fn.Name() == otherName {
+			return fn.(*types.Func)
+		}
+	}
+	return nil
+}
+
+// signatureDifference describes the first difference between the signature
+// of m and have (e.g., "has 1 result, want 2" or "parameter p is []int, want
+// []byte").
+func signatureDifference(m Method, have *types.Signature, q *qualifier) string {
+	if d := tupleDifference("parameter", m.In, have.Params(), q); d != "" {
+		return d
+	}
+	if d := tupleDifference("result", m.Out, have.Results(), q); d != "" {
+		return d
+	}
+	if have.Variadic() {
+		return "is variadic, want not variadic"
+	}
+	return "is not variadic, want variadic"
+}
+
+// tupleDifference describes the first difference between the parameters
+// (or results, as told by kind) want and have, or returns "" if they are of
+// the same types.
+func tupleDifference(kind string, want []Parameter, have *types.Tuple, q *qualifier) string {
+	if len(want) != have.Len() {
+		return fmt.Sprintf("has %s, want %d", plural(have.Len(), kind), len(want))
+	}
+	qualify := func(p *types.Package) string { return p.Path() }
+	for i, p := range want {
+		if p.Var == nil || !isValidType(p.Var.Type()) {
+			continue
+		}
+		if types.TypeString(p.Var.Type(), qualify) == types.TypeString(have.At(i).Type(), qualify) {
+			continue
+		}
+		name := have.At(i).Name()
+		if name == "" || name == "_" {
+			name = p.Name
+		}
+		if name == "" || name == "_" {
+			name = fmt.Sprintf("%d", i+1)
+		}
+		return fmt.Sprintf("%s %s is %s, want %s", kind, name, q.typeString(have.At(i).Type()), p.Type)
+	}
+	return ""
+}
+
+// plural returns n followed by noun, in plural unless n is 1 (e.g., "2
+// results").
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,147 @@
+package impl
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		typeName      string
+		want          []string // "<kind> <method> <file>:<line>"
+	}{
+		{
+			"impl/impl/test_data/panther.ClawReadWriter",
+			"*FullClaw",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.ClawReadWriter",
+			"FullClaw",
+			[]string{
+				"pointer receiver Read implementers.go:33",
+				"pointer receiver Write implementers.go:37",
+			},
+		},
+		{
+			"impl/impl/test_data/panther.ClawReadWriter",
+			"*HalfClaw",
+			[]string{
+				"missing method Puncture implementers.go:18",
+				"wrong signature Read implementers.go:23",
+				"missing method Write implementers.go:20",
+			},
+		},
+		{
+			"impl/impl/test_data/panther.ClawReadWriter",
+			"NearClaw",
+			[]string{
+				"pointer receiver Hardness implementers.go:45",
+				"unexported method Puncture implementers.go:49",
+				"wrong signature Read implementers.go:51",
+				"missing method Write implementers.go:42",
+			},
+		},
+		{
+			"go/types.Type",
+			"*FullClaw",
+			[]string{
+				"missing method Underlying implementers.go:27",
+				"missing method String implementers.go:27",
+			},
+		},
+	}
+
+	opts := Options{PkgPath: "impl/impl/test_data/panther"}
+	for _, c := range cases {
+		result, err := Check(c.interfacePath, c.typeName, opts)
+		if err != nil {
+			t.Errorf("Check(%q, %q, ...) failed: %s", c.interfacePath, c.typeName, err)
+			continue
+		}
+		var got []string
+		for _, p := range result.Problems {
+			got = append(got, fmt.Sprintf("%s %s %s:%d", p.Kind, p.Method, filepath.Base(p.Pos.Filename), p.Pos.Line))
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Check(%q, %q, ...) == %q, want %q", c.interfacePath, c.typeName, got, c.want)
+		}
+	}
+}
+
+func TestCheck_DescribesProblems(t *testing.T) {
+	cases := []struct {
+		typeName string
+		method   string
+		want     string
+	}{
+		{"NearClaw", "Read", "NearClaw does not implement ClawReadWriter (wrong type for method Read: parameter p is []int, want []byte)\n" +
+			"\thave Read(p []int) (int, error)\n" +
+			"\twant Read(p []byte) (n int, err error)"},
+		{"*HalfClaw", "Read", "*HalfClaw does not implement ClawReadWriter (wrong type for method Read: has 1 result, want 2)\n" +
+			"\thave Read(p []byte) int\n" +
+			"\twant Read(p []byte) (n int, err error)"},
+		{"*HalfClaw", "Puncture", "*HalfClaw does not implement ClawReadWriter (missing method Puncture)\n" +
+			"\twant Puncture(strength int)"},
+		{"NearClaw", "Puncture", "NearClaw does not implement ClawReadWriter (missing method Puncture: it has puncture instead)"},
+		{"NearClaw", "Hardness", "NearClaw does not implement ClawReadWriter (method Hardness has pointer receiver)"},
+		{"NearClaw", "Write", "NearClaw does not implement ClawReadWriter (Write is a field, not a method)"},
+	}
+
+	opts := Options{PkgPath: "impl/impl/test_data/panther"}
+	for _, c := range cases {
+		result, err := Check("impl/impl/test_data/panther.ClawReadWriter", c.typeName, opts)
+		if err != nil {
+			t.Errorf("Check(..., %q, ...) failed: %s", c.typeName, err)
+			continue
+		}
+		found := false
+		for _, p := range result.Problems {
+			if p.Method != c.method {
+				continue
+			}
+			found = true
+			if p.Message != c.want {
+				t.Errorf("Check(..., %q, ...) described %s as %q, want %q", c.typeName, c.method, p.Message, c.want)
+			}
+		}
+		if !found {
+			t.Errorf("Check(..., %q, ...) found no problem with %s", c.typeName, c.method)
+		}
+	}
+}
+
+func TestCheck_DescribesOtherCaseOfNonASCIINames(t *testing.T) {
+	result, err := Check("impl/impl/test_data/panther.Poised", "NearPoise", Options{PkgPath: "impl/impl/test_data/panther"})
+	if err != nil {
+		t.Fatalf("Check(...) failed: %s", err)
+	}
+	var got []string
+	for _, p := range result.Problems {
+		got = append(got, p.Message)
+	}
+	want := []string{"NearPoise does not implement Poised (missing method Élan: it has élan instead)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check(...) reported %q, want %q", got, want)
+	}
+}
+
+func TestCheck_UnexportedInterfaceMethods(t *testing.T) {
+	result, err := Check("go/ast.Expr", "*FullClaw", Options{PkgPath: "impl/impl/test_data/panther"})
+	if err != nil {
+		t.Fatalf("Check(...) failed: %s", err)
+	}
+	var got []string
+	for _, p := range result.Problems {
+		if p.Kind == UnexportedMethod {
+			got = append(got, p.Message)
+		}
+	}
+	want := []string{"*FullClaw cannot implement ast.Expr (method exprNode is unexported in package go/ast)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check(...) reported %q, want %q", got, want)
+	}
+}
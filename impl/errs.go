@@ -2,6 +2,7 @@ package impl
 
 import (
 	"fmt"
+	"strings"
 )
 
 type EmptyInterfacePathError struct {
@@ -87,3 +88,85 @@ func NewInvalidMethodNameError(message string, args ...interface{}) *InvalidMeth
 func (e *InvalidMethodNameError) Error() string {
 	return e.message
 }
+
+type InvalidTypeArgumentsError struct {
+	message string
+}
+
+func NewInvalidTypeArgumentsError(message string, args ...interface{}) *InvalidTypeArgumentsError {
+	return &InvalidTypeArgumentsError{fmt.Sprintf(message, args...)}
+}
+
+func (e *InvalidTypeArgumentsError) Error() string {
+	return e.message
+}
+
+type InvalidReceiverError struct {
+	message string
+}
+
+func NewInvalidReceiverError(message string, args ...interface{}) *InvalidReceiverError {
+	return &InvalidReceiverError{fmt.Sprintf(message, args...)}
+}
+
+func (e *InvalidReceiverError) Error() string {
+	return e.message
+}
+
+type UnhandledConstructError struct {
+	message string
+
+	// Warnings describe the constructs that could not be handled.
+	Warnings []Warning
+}
+
+func NewUnhandledConstructError(warnings []Warning) *UnhandledConstructError {
+	lines := make([]string, len(warnings))
+	for i, w := range warnings {
+		lines[i] = w.String()
+	}
+	return &UnhandledConstructError{
+		message:  fmt.Sprintf("the interface has constructs that could not be handled:\n%s", strings.Join(lines, "\n")),
+		Warnings: warnings,
+	}
+}
+
+func (e *UnhandledConstructError) Error() string {
+	return e.message
+}
+
+type InvalidModeError struct {
+	message string
+}
+
+func NewInvalidModeError(message string, args ...interface{}) *InvalidModeError {
+	return &InvalidModeError{fmt.Sprintf(message, args...)}
+}
+
+func (e *InvalidModeError) Error() string {
+	return e.message
+}
+
+type UnserializableTypeError struct {
+	message string
+}
+
+func NewUnserializableTypeError(message string, args ...interface{}) *UnserializableTypeError {
+	return &UnserializableTypeError{fmt.Sprintf(message, args...)}
+}
+
+func (e *UnserializableTypeError) Error() string {
+	return e.message
+}
+
+type UnsupportedMethodError struct {
+	message string
+}
+
+func NewUnsupportedMethodError(message string, args ...interface{}) *UnsupportedMethodError {
+	return &UnsupportedMethodError{fmt.Sprintf(message, args...)}
+}
+
+func (e *UnsupportedMethodError) Error() string {
+	return e.message
+}
@@ -0,0 +1,67 @@
+package impl
+
+import (
+	"io"
+	"text/template"
+)
+
+// fakeMethod is a method of a fake, whose parameters are all named so that
+// they can be passed on to its func field.
+type fakeMethod struct {
+	Method
+	Recv string // the name of the fake's receiver
+	Args string // the arguments passed on to the func field (e.g., "p, opts...")
+}
+
+var fakeTmpl = template.Must(template.New("fake").Parse(
+	"// {{.Type}} is a fake {{.Interface}} whose methods call the func fields named after them.\n" +
+		"type {{.Type}} struct {\n" +
+		"{{range .Methods}}{{.Name}}Func func" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if ne (len .Out) 0}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}}\n{{end}}" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if ne (len .Out) 0}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"if {{.Recv}}.{{.Name}}Func == nil {\n" +
+		"panic(\"{{$.Type}}.{{.Name}} was called, but {{$.Type}}.{{.Name}}Func is nil\")\n" +
+		"}\n" +
+		"{{if ne (len .Out) 0}}return {{end}}{{.Recv}}.{{.Name}}Func({{.Args}})\n" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderFake writes the declaration of the receiver's type as a fake of the
+// interface i, along with its methods.
+func renderFake(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Fake); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to fake", i.Name)
+	}
+	var fields []string
+	for _, m := range i.Methods {
+		fields = append(fields, m.Name+"Func")
+	}
+	if err := checkMembers(i, Fake, fields...); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Receiver  string
+		Interface string
+		Methods   []fakeMethod
+	}{Type: recv.typeName, Receiver: recv.typeString(), Interface: i.Name}
+	for _, m := range i.Methods {
+		reserved := i.reservedNames(m, recvName)
+		taken := takenNames(reserved, m.In, m.Out)
+		m.In = namedParams(m.In, "p", reserved, taken)
+		m.Out = namedParams(m.Out, "", reserved, taken)
+		data.Methods = append(data.Methods, fakeMethod{Method: m, Recv: recvName, Args: callArgs(m.In)})
+	}
+
+	return renderTemplate(fakeTmpl, data, w)
+}
@@ -0,0 +1,154 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestImplWithOptions_Fake(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          string
+	}{
+		{
+			"io.ReadWriter",
+			"f *FakeReadWriter",
+			`// FakeReadWriter is a fake io.ReadWriter whose methods call the func fields named after them.
+type FakeReadWriter struct {
+	ReadFunc  func(p []byte) (n int, err error)
+	WriteFunc func(p []byte) (n int, err error)
+}
+
+func (f *FakeReadWriter) Read(p []byte) (n int, err error) {
+	if f.ReadFunc == nil {
+		panic("FakeReadWriter.Read was called, but FakeReadWriter.ReadFunc is nil")
+	}
+	return f.ReadFunc(p)
+}
+
+func (f *FakeReadWriter) Write(p []byte) (n int, err error) {
+	if f.WriteFunc == nil {
+		panic("FakeReadWriter.Write was called, but FakeReadWriter.WriteFunc is nil")
+	}
+	return f.WriteFunc(p)
+}
+
+`,
+		},
+		{
+			"impl/impl/test_data/panther.WithUnnamed",
+			"FakeRoarer",
+			`// FakeRoarer is a fake WithUnnamed whose methods call the func fields named after them.
+type FakeRoarer struct {
+	RoarFunc func(p0 context.Context, p1 string, p2 ...int) error
+	PurrFunc func(p0 int, loudness float64)
+}
+
+func (f FakeRoarer) Roar(p0 context.Context, p1 string, p2 ...int) error {
+	if f.RoarFunc == nil {
+		panic("FakeRoarer.Roar was called, but FakeRoarer.RoarFunc is nil")
+	}
+	return f.RoarFunc(p0, p1, p2...)
+}
+
+func (f FakeRoarer) Purr(p0 int, loudness float64) {
+	if f.PurrFunc == nil {
+		panic("FakeRoarer.Purr was called, but FakeRoarer.PurrFunc is nil")
+	}
+	f.PurrFunc(p0, loudness)
+}
+
+`,
+		},
+		{
+			"impl/impl/test_data/panther.WithUnnamed::Purr",
+			"p0 *FakePurrer",
+			`// FakePurrer is a fake WithUnnamed whose methods call the func fields named after them.
+type FakePurrer struct {
+	PurrFunc func(p0_ int, loudness float64)
+}
+
+func (p0 *FakePurrer) Purr(p0_ int, loudness float64) {
+	if p0.PurrFunc == nil {
+		panic("FakePurrer.Purr was called, but FakePurrer.PurrFunc is nil")
+	}
+	p0.PurrFunc(p0_, loudness)
+}
+
+`,
+		},
+		{
+			"impl/impl/test_data/panther.Namer",
+			"w *FakeNamer",
+			`// FakeNamer is a fake Namer whose methods call the func fields named after them.
+type FakeNamer struct {
+	NamesFunc func(err string, r int, f ...string) (w_ bool, e error)
+}
+
+func (w *FakeNamer) Names(err string, r int, f ...string) (w_ bool, e error) {
+	if w.NamesFunc == nil {
+		panic("FakeNamer.Names was called, but FakeNamer.NamesFunc is nil")
+	}
+	return w.NamesFunc(err, r, f...)
+}
+
+`,
+		},
+		{
+			"impl/impl/test_data/panther.Namer",
+			"r *FakeNamer",
+			`// FakeNamer is a fake Namer whose methods call the func fields named after them.
+type FakeNamer struct {
+	NamesFunc func(err string, r_ int, f ...string) (w bool, e error)
+}
+
+func (r *FakeNamer) Names(err string, r_ int, f ...string) (w bool, e error) {
+	if r.NamesFunc == nil {
+		panic("FakeNamer.Names was called, but FakeNamer.NamesFunc is nil")
+	}
+	return r.NamesFunc(err, r_, f...)
+}
+
+`,
+		},
+	}
+
+	opts := Options{PkgPath: "impl/impl/test_data/panther", Mode: Fake}
+	for _, c := range cases {
+		var w bytes.Buffer
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &w, opts)
+		if err != nil {
+			t.Errorf("ImplWithOptions(%q, %q, ...) failed: %s", c.interfacePath, c.receiver, err)
+			continue
+		}
+		if got := w.String(); got != c.want {
+			t.Errorf("ImplWithOptions(%q, %q, ...) wrote\n%s\nwant\n%s", c.interfacePath, c.receiver, got, c.want)
+		}
+	}
+}
+
+func TestImplWithOptions_FakeFails(t *testing.T) {
+	cases := []struct {
+		receiver string
+		opts     Options
+		want     error
+	}{
+		{"f *Fake[T]", Options{Mode: Fake}, &InvalidReceiverError{}},
+		{"", Options{Mode: Fake}, &InvalidReceiverError{}},
+		{"f *Fake", Options{Mode: Fake, Missing: true}, &InvalidModeError{}},
+		{"f *Fake", Options{Mode: Mode(-1)}, &InvalidModeError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions("io.Reader", c.receiver, &bytes.Buffer{}, c.opts)
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(\"io.Reader\", %q, %+v) == %v, want a %T", c.receiver, c.opts, err, c.want)
+		}
+	}
+
+	_, err := ImplWithOptions("impl/impl/test_data/panther.WithHelperNames", "f *FakeScratcher", &bytes.Buffer{}, Options{Mode: Fake})
+	if _, ok := err.(*InvalidInterfacePathError); !ok {
+		t.Errorf("ImplWithOptions(%q, ...) == %v, want a %T", "impl/impl/test_data/panther.WithHelperNames", err, &InvalidInterfacePathError{})
+	}
+}
@@ -0,0 +1,97 @@
+package impl
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// fallbackMethod is a method of a fallback decorator.
+type fallbackMethod struct {
+	forwardedMethod
+	Failed string // the results returned when all calls failed (e.g., "0, errors.Join(errs...)")
+
+	// Errs and Inner name the variables of the method, so that they do not
+	// shadow its parameters.
+	Errs, Inner string
+}
+
+var fallbackTmpl = template.Must(template.New("fallback").Parse(
+	"// {{.Type}} passes the calls made to it on to Primary and, for its methods\n" +
+		"// returning an error, on to each of Secondaries in turn while they fail.\n" +
+		"// It returns the results of the first call that succeeds, or the errors\n" +
+		"// joined if all of them fail.\n" +
+		"type {{.Type}} struct {\n" +
+		"Primary     {{.InnerType}}\n" +
+		"Secondaries []{{.InnerType}}\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{if .Err}}" +
+		"if {{.Results}} = {{$.Recv}}.Primary.{{.Name}}({{.Args}}); {{.Err}} == nil {\n" +
+		"return\n" +
+		"}\n" +
+		"{{.Errs}} := []error{ {{.Err}} }\n" +
+		"for _, {{.Inner}} := range {{$.Recv}}.Secondaries {\n" +
+		"if {{.Results}} = {{.Inner}}.{{.Name}}({{.Args}}); {{.Err}} == nil {\n" +
+		"return\n" +
+		"}\n" +
+		"{{.Errs}} = append({{.Errs}}, {{.Err}})\n" +
+		"}\n" +
+		"return {{.Failed}}\n" +
+		"{{else}}" +
+		"{{if .Out}}return {{end}}{{$.Recv}}.Primary.{{.Name}}({{.Args}})\n" +
+		"{{end}}" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderFallback writes the declaration of the receiver's type as a fallback
+// decorator of the interface i, which passes the calls made to it on to a
+// primary implementation, along with its methods. Methods whose last result
+// is an error pass calls that fail on to each of several secondary
+// implementations in turn, and return the results of the first call that
+// succeeds, or zero values along with the errors joined with errors.Join if
+// all of them fail. Other methods only pass calls on to the primary
+// implementation.
+func renderFallback(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Fallback); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to fall back on", i.Name)
+	}
+	if err := checkMembers(i, Fallback, "Primary", "Secondaries"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		InnerType string
+		Methods   []fallbackMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		InnerType: i.useType(),
+	}
+	for _, m := range forwardedMethods(i, recvName, i.packageName("errors", "errors")) {
+		fm := fallbackMethod{forwardedMethod: m}
+		if m.Err != "" {
+			fm.Errs = freeName("errs", m.taken)
+			fm.Inner = freeName("inner", m.taken)
+			failed := make([]string, len(m.Out))
+			for j, p := range m.Out[:len(m.Out)-1] {
+				failed[j] = zeroValue(p)
+			}
+			failed[len(m.Out)-1] = i.importName("errors", "errors") + ".Join(" + fm.Errs + "...)"
+			fm.Failed = strings.Join(failed, ", ")
+		}
+		data.Methods = append(data.Methods, fm)
+	}
+	return renderTemplate(fallbackTmpl, data, w)
+}
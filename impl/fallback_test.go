@@ -0,0 +1,117 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Fallback(t *testing.T) {
+	var w bytes.Buffer
+	result, err := ImplWithOptions("impl/impl/test_data/panther.Cabinet", "f *FallbackCabinet", &w, Options{Mode: Fallback})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := `// FallbackCabinet passes the calls made to it on to Primary and, for its methods
+// returning an error, on to each of Secondaries in turn while they fail.
+// It returns the results of the first call that succeeds, or the errors
+// joined if all of them fail.
+type FallbackCabinet struct {
+	Primary     panther.Cabinet
+	Secondaries []panther.Cabinet
+}
+
+func (f *FallbackCabinet) Get(ctx context.Context, key string) (r0 string, r1 error) {
+	if r0, r1 = f.Primary.Get(ctx, key); r1 == nil {
+		return
+	}
+	errs := []error{r1}
+	for _, inner := range f.Secondaries {
+		if r0, r1 = inner.Get(ctx, key); r1 == nil {
+			return
+		}
+		errs = append(errs, r1)
+	}
+	return "", errors.Join(errs...)
+}
+
+func (f *FallbackCabinet) Put(ctx context.Context, key string, value string) (r0 error) {
+	if r0 = f.Primary.Put(ctx, key, value); r0 == nil {
+		return
+	}
+	errs := []error{r0}
+	for _, inner := range f.Secondaries {
+		if r0 = inner.Put(ctx, key, value); r0 == nil {
+			return
+		}
+		errs = append(errs, r0)
+	}
+	return errors.Join(errs...)
+}
+
+func (f *FallbackCabinet) Watch(ctx context.Context, key string) (r0 <-chan string) {
+	return f.Primary.Watch(ctx, key)
+}
+
+func (f *FallbackCabinet) Len() (r0 int) {
+	return f.Primary.Len()
+}
+
+`
+	if got := w.String(); got != want {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwant\n%s", got, want)
+	}
+	wantImports := []Import{{Path: "context"}, {Path: "impl/impl/test_data/panther"}, {Path: "errors"}}
+	if !reflect.DeepEqual(result.Imports, wantImports) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, wantImports)
+	}
+}
+
+func TestImplWithOptions_FallbackNamesItsVariables(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("io.ReaderAt", "f FallbackReaderAt", &w, Options{Mode: Fallback})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := "\terrs := []error{err}\n" +
+		"\tfor _, inner := range f.Secondaries {\n" +
+		"\t\tif n, err = inner.ReadAt(p, off); err == nil {\n"
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+	}
+}
+
+func TestImplWithOptions_FallbackRenamesShadowingParameters(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Shadower::Notify", "f FallbackShadower", &w, Options{Mode: Fallback})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"func (f FallbackShadower) Notify(errors_ []error) (r0 error) {\n",
+		"\t\tif r0 = inner.Notify(errors_); r0 == nil {\n",
+		"\treturn errors.Join(errs...)\n",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_FallbackFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "f *FallbackReader[T]", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.Finisher::Finish", "f *FallbackFinisher", nil},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Fallback})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package impl
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"strings"
+)
+
+// methodSet holds the methods of an instance of a generic interface, which
+// replace the generic methods declared in source.
+type methodSet struct {
+	named   *types.Named // the instance
+	methods map[string]*types.Func
+}
+
+// instanceMethods returns the methods of t if it is an instance of a generic
+// interface, or nil otherwise.
+func instanceMethods(t *types.Named) *methodSet {
+	iface, ok := t.Underlying().(*types.Interface)
+	if !ok || t.TypeArgs().Len() == 0 {
+		return nil
+	}
+	methods := make(map[string]*types.Func, iface.NumMethods())
+	for i := 0; i < iface.NumMethods(); i++ {
+		methods[iface.Method(i).Name()] = iface.Method(i)
+	}
+	return &methodSet{named: t, methods: methods}
+}
+
+// method returns the instantiated method with the given name, if s has it.
+func (s *methodSet) method(name string) (*types.Func, bool) {
+	if s == nil {
+		return nil, false
+	}
+	fn, ok := s.methods[name]
+	return fn, ok
+}
+
+// embedded returns the methods of the instance of the interface pkgPath.name
+// that s embeds, or s if it cannot be found.
+func (s *methodSet) embedded(pkgPath, name string) *methodSet {
+	iface, _ := s.named.Underlying().(*types.Interface)
+	for i := 0; iface != nil && i < iface.NumEmbeddeds(); i++ {
+		if named, ok := types.Unalias(iface.EmbeddedType(i)).(*types.Named); ok && isObject(named.Obj(), pkgPath, name) {
+			if embedded := instanceMethods(named); embedded != nil {
+				return embedded
+			}
+		}
+	}
+	return s
+}
+
+// typeArgs returns the type parameters of the interface declared by
+// typeSpec in pkg, by name, with the type arguments they stand for in s.
+// Without s, the methods are left generic, so they stand for themselves. If
+// s is not an instance of the interface, they stand for nil: their type
+// arguments are not known.
+func (s *methodSet) typeArgs(pkg *typedPackage, typeSpec *ast.TypeSpec) map[string]types.Type {
+	obj := pkg.info.Defs[typeSpec.Name]
+	if obj == nil {
+		return nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() == 0 {
+		return nil
+	}
+	instance := s != nil && isObject(s.named.Origin().Obj(), pkg.ImportPath, typeSpec.Name.Name) &&
+		s.named.TypeArgs().Len() == named.TypeParams().Len()
+	args := make(map[string]types.Type, named.TypeParams().Len())
+	for i := 0; i < named.TypeParams().Len(); i++ {
+		param := named.TypeParams().At(i)
+		switch {
+		case s == nil:
+			args[param.Obj().Name()] = param
+		case instance:
+			args[param.Obj().Name()] = s.named.TypeArgs().At(i)
+		default:
+			args[param.Obj().Name()] = nil
+		}
+	}
+	return args
+}
+
+// isObject reports whether obj is declared with the given name in the
+// package with import path pkgPath. Objects are compared by name, since a
+// package may be type-checked more than once.
+func isObject(obj types.Object, pkgPath, name string) bool {
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
+
+// splitTypeArgs splits the type arguments from an interface name (e.g.,
+// splits "Store[string, *User]" into "Store" and the expressions "string"
+// and "*User").
+func splitTypeArgs(interfaceName string) (string, []ast.Expr, error) {
+	if !strings.Contains(interfaceName, "[") {
+		return interfaceName, nil, nil
+	}
+	expr, err := parser.ParseExpr(interfaceName)
+	if err != nil {
+		return "", nil, NewInvalidImportFormatError("invalid type arguments in %q: %s", interfaceName, err)
+	}
+	var x ast.Expr
+	var typeArgs []ast.Expr
+	switch expr := expr.(type) {
+	case *ast.IndexExpr:
+		x, typeArgs = expr.X, []ast.Expr{expr.Index}
+	case *ast.IndexListExpr:
+		x, typeArgs = expr.X, expr.Indices
+	}
+	ident, ok := x.(*ast.Ident)
+	if !ok {
+		return "", nil, NewInvalidImportFormatError(
+			"interface %q must be a name followed by type arguments (e.g., \"Store[string, int]\")", interfaceName)
+	}
+	return ident.Name, typeArgs, nil
+}
+
+// instantiate instantiates the generic interface declared by typeSpec in
+// pkg with typeArgs and returns its instantiated methods. The type
+// arguments are written as they would be in a method of recv in a file of
+// package opts.PkgPath with imports opts.Imports, so they can refer to the
+// receiver type's type parameters. The interface's package can also be
+// referred to by its name.
+func instantiate(pkg *typedPackage, typeSpec *ast.TypeSpec, typeArgs []ast.Expr, recv *receiver, opts Options) (*methodSet, error) {
+	named, ok := pkg.info.Defs[typeSpec.Name].Type().(*types.Named)
+	if !ok || named.TypeParams().Len() == 0 {
+		return nil, NewInvalidTypeArgumentsError("interface %q is not generic but got type arguments", typeSpec.Name.Name)
+	}
+	if got, want := len(typeArgs), named.TypeParams().Len(); got != want {
+		return nil, NewInvalidTypeArgumentsError("interface %q has %d type parameters but got %d type arguments",
+			typeSpec.Name.Name, want, got)
+	}
+	args, err := evalTypeArgs(typeArgs, pkg.types, recv, opts)
+	if err != nil {
+		return nil, err
+	}
+	// The constraints of the receiver's type parameters are not known, so
+	// whether they satisfy the interface's constraints is not validated.
+	validate := len(recv.typeParams) == 0
+	inst, err := types.Instantiate(nil, named, args, validate)
+	if err != nil {
+		return nil, NewInvalidTypeArgumentsError("could not instantiate interface %q: %s", typeSpec.Name.Name, err)
+	}
+	return instanceMethods(inst.(*types.Named)), nil
+}
+
+// evalTypeArgs evaluates the type arguments typeArgs of an interface of
+// package ifacePkg. See instantiate.
+func evalTypeArgs(typeArgs []ast.Expr, ifacePkg *types.Package, recv *receiver, opts Options) ([]types.Type, error) {
+	l := loaderFor(opts.Dir)
+	// The interface's package and the package the scaffolding is written to
+	// are type-checked even if they have errors, unlike other imports.
+	checked := map[string]*types.Package{ifacePkg.Path(): ifacePkg}
+	if opts.PkgPath != "" && opts.PkgPath != ifacePkg.Path() {
+		if pkg, err := l.load(opts.PkgPath, ""); err == nil {
+			checked[opts.PkgPath] = pkg.types
+		}
+	}
+
+	var src bytes.Buffer
+	names := map[string]bool{}
+	fmt.Fprintln(&src, "package typeargs")
+	if checked[opts.PkgPath] != nil {
+		fmt.Fprintf(&src, "import . %q\n", opts.PkgPath)
+	}
+	for _, imp := range opts.Imports {
+		name := imp.Name
+		if name == "" {
+			name = assumedName(imp.Path)
+		}
+		if name == "_" || name == "." || names[name] {
+			continue
+		}
+		names[name] = true
+		fmt.Fprintf(&src, "import %s %q\n", name, imp.Path)
+	}
+	if ifacePkg.Path() != opts.PkgPath && !names[ifacePkg.Name()] {
+		fmt.Fprintf(&src, "import %s %q\n", ifacePkg.Name(), ifacePkg.Path())
+	}
+	// The type arguments are evaluated in the body of a function with the
+	// receiver type's type parameters.
+	typeParams := ""
+	if len(recv.typeParams) > 0 {
+		typeParams = "[" + strings.Join(recv.typeParams, ", ") + " any]"
+	}
+	fmt.Fprintf(&src, "func _%s() {\n}\n", typeParams)
+
+	l.Lock()
+	defer l.Unlock()
+	fset := l.fset
+	file, err := parser.ParseFile(fset, "typeargs.go", src.Bytes(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the imports for the type arguments:\n%s\n: %s", src.Bytes(), err)
+	}
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if pkg, ok := checked[path]; ok {
+				return pkg, nil
+			}
+			return l.Import(path)
+		}),
+		Error: func(err error) {
+			dl("    type error evaluating type arguments: %s", err)
+		},
+	}
+	scope, _ := conf.Check("typeargs", fset, []*ast.File{file}, nil)
+
+	pos := file.Decls[len(file.Decls)-1].(*ast.FuncDecl).Body.Rbrace
+	args := make([]types.Type, len(typeArgs))
+	for i, typeArg := range typeArgs {
+		expr := types.ExprString(typeArg)
+		tv, err := types.Eval(fset, scope, pos, expr)
+		if err != nil {
+			return nil, NewInvalidTypeArgumentsError("could not resolve type argument %q: %s", expr, err)
+		}
+		if !tv.IsType() {
+			return nil, NewInvalidTypeArgumentsError("type argument %q is not a type", expr)
+		}
+		args[i] = tv.Type
+	}
+	return args, nil
+}
+
+// importerFunc implements types.Importer with a function.
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
@@ -4,12 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
-	"go/build"
 	"go/format"
-	"go/parser"
 	"go/token"
+	"go/types"
 	"io"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -18,12 +17,26 @@ import (
 
 // parseImport splits impPath into the package part and the interface name part
 // (e.g., splits "io.Reader" into "io" and "Reader"). It also supports specifying
-// a method within an interface using "::" (e.g., "io.ReadWriter::Read" ).
+// a method within an interface using "::" (e.g., "io.ReadWriter::Read" ), and
+// type arguments for generic interfaces, which are kept in the interface name
+// part (e.g., splits "example.com/cache.Store[string, *User]" into
+// "example.com/cache" and "Store[string, *User]").
 func parseImport(impPath string) (pkgPath, interfaceName, methodName string, err error) {
 	if len(strings.TrimSpace(impPath)) < 1 {
 		return "", "", "", NewInvalidImportFormatError("import path cannot be empty")
 	}
 
+	// Type arguments can have dots and "::" of their own, so they are set
+	// aside while splitting.
+	typeArgs := ""
+	if i := strings.Index(impPath, "["); i >= 0 {
+		j := strings.LastIndex(impPath, "]")
+		if j < i {
+			return "", "", "", NewInvalidImportFormatError("type arguments in %q are missing a closing \"]\"", impPath)
+		}
+		impPath, typeArgs = impPath[:i]+impPath[j+1:], impPath[i:j+1]
+	}
+
 	parts := strings.Split(impPath, ".")
 	if len(parts) < 2 {
 		return "", "", "", NewInvalidImportFormatError(
@@ -32,7 +45,7 @@ func parseImport(impPath string) (pkgPath, interfaceName, methodName string, err
 
 	pkgPath = strings.Trim(strings.Join(parts[:len(parts)-1], "."), ".")
 	interfaceAndMethod := strings.Split(parts[len(parts)-1], "::")
-	interfaceName = interfaceAndMethod[0]
+	interfaceName = interfaceAndMethod[0] + typeArgs
 	if len(interfaceAndMethod) > 1 {
 		methodName = strings.Join(interfaceAndMethod[1:], "")
 	}
@@ -48,7 +61,7 @@ func formatInterface(path string) (string, error) {
 	srcWithInterface := []byte(fmt.Sprintf("package p;var r %s", path))
 	srcB, err := imports.Process("", srcWithInterface, nil)
 	if err != nil {
-		return "", NewInvalidInterfacePathError("invalid interface: ", err)
+		return "", NewInvalidInterfacePathError("invalid interface: %s", err)
 	}
 
 	src := string(srcB)
@@ -61,25 +74,36 @@ func formatInterface(path string) (string, error) {
 	return parts[0], nil
 }
 
-// buildPackage returns a *build.Package from the given package path.
-func buildPackage(pkgPath string) (pkg *build.Package, err error) {
-	pkg, err = build.Import(pkgPath, "", 0)
-	if err != nil {
-		err = NewCouldNotFindPackageError("could not find interface's package (%q): %s", pkgPath, err)
+// fileImportPath returns the path of the package imported as name by file.
+func fileImportPath(file *ast.File, name string) (string, bool) {
+	if file == nil {
+		return "", false
 	}
-	return
-}
-
-func interfaceTypeSpec(name string, pkg *build.Package) (ts *ast.TypeSpec, err error) {
-	unparsedFiles := []string{}
-	fset := token.NewFileSet()
-	for _, fileName := range pkg.GoFiles {
-		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, fileName), nil, 0)
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
 		if err != nil {
-			unparsedFiles = append(unparsedFiles, fileName)
 			continue
 		}
+		if spec.Name != nil && spec.Name.Name == name ||
+			spec.Name == nil && assumedName(path) == name {
+			return path, true
+		}
+	}
+	return "", false
+}
 
+// fileOf returns the file of the package that contains node.
+func (pkg *typedPackage) fileOf(node ast.Node) *ast.File {
+	for _, file := range pkg.files {
+		if file.FileStart <= node.Pos() && node.End() <= file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+func interfaceTypeSpec(name string, pkg *typedPackage) (ts *ast.TypeSpec, err error) {
+	for _, file := range pkg.files {
 		for _, decl := range file.Decls {
 			if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
 				for _, spec := range decl.Specs {
@@ -93,40 +117,62 @@ func interfaceTypeSpec(name string, pkg *build.Package) (ts *ast.TypeSpec, err e
 
 	err = NewInterfaceNotFoundError("could not find interface %q when parsing package %q",
 		name, pkg.Name)
-	if len(unparsedFiles) > 0 {
+	if len(pkg.unparsed) > 0 {
 		err = NewInterfaceNotFoundError("%s: the following files could not be parsed: %q",
-			err, unparsedFiles)
+			err, pkg.unparsed)
 	}
 	return
 }
 
-func buildMethod(name string, funcType *ast.FuncType) Method {
+// buildMethod builds a method from its declaration and from fn, the
+// type-checked method. fn may be nil for function types that are not
+// methods, in which case the parameters are built from source only. Types are
+// qualified by q.
+func buildMethod(name string, funcType *ast.FuncType, fn *types.Func, q *qualifier) Method {
+	q = q.forMethod(name)
+	var sig *types.Signature
+	var params, results *types.Tuple
+	if fn != nil {
+		sig = fn.Type().(*types.Signature)
+		params, results = sig.Params(), sig.Results()
+	}
 	dl("    method has input parameters?\t%t - Adding them\n", funcType.Params != nil)
-	in := buildParams(funcType.Params)
+	in := buildParams(funcType.Params, params, q)
 	dl("    method has results?\t%t - Adding them\n", funcType.Results != nil)
-	out := buildParams(funcType.Results)
-	return NewMethod(name, in, out)
+	out := buildParams(funcType.Results, results, q)
+	m := NewMethod(name, in, out)
+	m.Signature = sig
+	return m
 }
 
-func buildParams(fl *ast.FieldList) []Parameter {
+// buildParams builds the parameters declared in fl. The i-th parameter is
+// backed by the i-th variable of tuple, when there is one.
+func buildParams(fl *ast.FieldList, tuple *types.Tuple, q *qualifier) []Parameter {
 	if fl == nil || fl.List == nil || len(fl.List) == 0 {
 		dl("    nothing to add, empty list")
 		return []Parameter{}
 	}
 	params := make([]Parameter, 0, len(fl.List))
 	dl("    it has %d fields", len(fl.List))
+	paramVar := func() *types.Var {
+		if tuple == nil || len(params) >= tuple.Len() {
+			return nil
+		}
+		return tuple.At(len(params))
+	}
 	for ip, field := range fl.List {
 		dl("    attempting to build parameters from %dth field of type %T with names %v",
 			ip, field.Type, field.Names)
-		typeName := getParamTypeName(field)
 		if isUnamed := len(field.Names) == 0; isUnamed {
-			params = append(params, NewParameter("", typeName))
-			dl("    %dth unnamed field with typeName %q was added", ip, typeName)
+			v := paramVar()
+			params = append(params, newTypedParameter("", field, v, q))
+			dl("    %dth unnamed field was added", ip)
 		} else {
 			// Multiple names indicate an "i, j int" situation.
 			// 1 field, 1 type, multiple parameters.
 			for jp, fieldName := range field.Names {
-				params = append(params, NewParameter(fieldName.Name, typeName))
+				v := paramVar()
+				params = append(params, newTypedParameter(fieldName.Name, field, v, q))
 				dl("    %d-%dth field was added", ip, jp)
 			}
 		}
@@ -134,87 +180,205 @@ func buildParams(fl *ast.FieldList) []Parameter {
 	return params
 }
 
-func getParamTypeName(field *ast.Field) (typeName string) {
-	return getExprTypeName(field.Type)
+// newTypedParameter creates a parameter with the given name for v, which
+// is declared by field. Its type is qualified by q.
+func newTypedParameter(name string, field *ast.Field, v *types.Var, q *qualifier) Parameter {
+	typeName, imports := q.record(func() string { return getParamTypeName(field, v, q) })
+	p := NewParameter(name, typeName)
+	p.Var = v
+	p.Imports = imports
+	return p
 }
 
-func getExprTypeName(fieldTypeExpr ast.Expr) (typeName string) {
-	switch fieldType := fieldTypeExpr.(type) {
-	case *ast.Ellipsis:
-		typeName = "..." + getExprTypeName(fieldType.Elt)
-	case *ast.Ident:
-		typeName = fieldType.Name
-	case *ast.ArrayType:
-		if typeN := getExprTypeName(fieldType.Elt); len(typeN) > 0 {
-			typeName = "[]" + typeN
-			break
+// getParamTypeName returns the name of the type of field, which declares v,
+// qualified by q. The name is printed from the type-checked type unless the
+// type checker could not resolve it, in which case it is printed from the
+// source.
+func getParamTypeName(field *ast.Field, v *types.Var, q *qualifier) string {
+	if v == nil || !isValidType(v.Type()) {
+		return getExprTypeName(field.Type, q)
+	}
+	if ellipsis, ok := field.Type.(*ast.Ellipsis); ok {
+		if slice, ok := v.Type().(*types.Slice); ok {
+			return "..." + q.typeString(slice.Elem())
 		}
-		dl("    field of type %T with Elt %T was NOT added",
-			fieldType, fieldType.Elt)
-	case *ast.SelectorExpr:
-		typeName = fieldType.Sel.Name
-		if ident, ok := fieldType.X.(*ast.Ident); ok {
-			typeName = fmt.Sprintf("%s.%s", ident.Name, typeName)
-			break
+		return "..." + getExprTypeName(ellipsis.Elt, q)
+	}
+	return q.typeString(v.Type())
+}
+
+// isValidType reports whether t, and every type it is composed of, was
+// resolved by the type checker.
+func isValidType(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Basic:
+		return t.Kind() != types.Invalid
+	case *types.Map:
+		return isValidType(t.Key()) && isValidType(t.Elem())
+	case interface{ Elem() types.Type }: // pointers, slices, arrays and channels
+		return isValidType(t.Elem())
+	case *types.Signature:
+		return isValidType(t.Params()) && isValidType(t.Results())
+	case *types.Tuple:
+		for i := 0; i < t.Len(); i++ {
+			if !isValidType(t.At(i).Type()) {
+				return false
+			}
 		}
-		dl("    field of type %T with X %T was NOT added",
-			fieldType, fieldType.X)
-	case *ast.InterfaceType:
-		typeName = "interface{}"
-	case *ast.StarExpr:
-		if ident, ok := fieldType.X.(*ast.Ident); ok {
-			typeName = "*" + ident.Name
-			break
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if !isValidType(t.Field(i).Type()) {
+				return false
+			}
 		}
-		if expr, ok := fieldType.X.(*ast.SelectorExpr); ok {
-			typeName = fmt.Sprintf("*%s.%s", getExprTypeName(expr.X), expr.Sel.String())
-			break
+	case *types.Interface:
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			if !isValidType(t.EmbeddedType(i)) {
+				return false
+			}
 		}
-		dl("    field of type *ast.StarExpr with .X %T was NOT added: %+v", fieldType.X, fieldType.X)
-	case *ast.FuncType:
-		method := buildMethod("", fieldType)
-		ins := ""
-		outs := ""
-		for _, param := range method.In {
-			ins = ins + fmt.Sprintf("%s %s", param.Name, param.Type)
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			if !isValidType(t.ExplicitMethod(i).Type()) {
+				return false
+			}
 		}
-		for _, param := range method.Out {
-			outs = outs + fmt.Sprintf("%s %s", param.Name, param.Type)
+	case *types.Union:
+		for i := 0; i < t.Len(); i++ {
+			if !isValidType(t.Term(i).Type()) {
+				return false
+			}
 		}
-		typeName = fmt.Sprintf("func (%s) (%s)", ins, outs)
-	case *ast.MapType:
-		keyType := getExprTypeName(fieldType.Key)
-		valType := getExprTypeName(fieldType.Value)
-		typeName = fmt.Sprintf("map[%s]%s", keyType, valType)
-	case *ast.ChanType:
-		typeName = "chan " + getExprTypeName(fieldType.Value)
-	default:
-		dl("    field of type %T was NOT added", fieldType)
 	}
-	return
+	return true
 }
 
-// BuildInterface generates a model Interface from the given internal
-// or external  path. The path is expected to be in the format of
+// buildInterface generates a model Interface from the given internal
+// or external path. The path is expected to be in the format of
 // <package>.<interface>. For example, "io.Reader" or
-// "impl/test_data/panther.Clawable".
-func buildInterface(path string) (*Interface, error) {
+// "impl/test_data/panther.Clawable". Packages are resolved from opts.Dir.
+// Types are qualified as they would be written in the package with import
+// path opts.PkgPath, in a file with imports opts.Imports, for methods of
+// recv. With opts.Missing, only the methods recv's type does not have yet
+// are built.
+func buildInterface(path string, recv *receiver, opts Options) (*Interface, error) {
 	pkgPath, interfaceName, methodName, err := parseImport(path)
 	if err != nil {
 		return nil, err
 	}
+	interfaceName, typeArgs, err := splitTypeArgs(interfaceName)
+	if err != nil {
+		return nil, err
+	}
 	interfaceName, err = formatInterface(interfaceName)
 	if err != nil {
 		return nil, err
 	}
-	pkg, err := buildPackage(pkgPath)
+	l := loaderFor(opts.Dir)
+	pkg, typeSpec, inst, err := findInterface(l, pkgPath, "", interfaceName)
+	if err != nil {
+		return nil, err
+	}
+	if len(typeArgs) == 0 && typeSpec.TypeParams != nil && inst == nil {
+		typeArgs, err = recv.typeArgs(interfaceName, typeSpec.TypeParams.NumFields())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(typeArgs) > 0 {
+		inst, err = instantiate(pkg, typeSpec, typeArgs, recv, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	q := newQualifier(opts.PkgPath, opts.Imports)
+	methods, err := interfaceMethods(pkg, typeSpec, inst, q)
 	if err != nil {
 		return nil, err
 	}
-	typeSpec, err := interfaceTypeSpec(interfaceName, pkg)
+	methods, err = filterMethod(methods, methodName)
 	if err != nil {
 		return nil, err
 	}
+	var mismatches []Mismatch
+	if opts.Missing {
+		methods, mismatches = recv.missing(methods, opts.PkgPath, opts.Dir, q)
+	}
+	iface := NewInterface(methods)
+	iface.q = q
+	iface.Name = typeSpec.Name.Name
+	if qual := q.qualifyPath(pkg.ImportPath, pkg.Name); qual != "" {
+		iface.Name = qual + "." + iface.Name
+	}
+	iface.Type, iface.TypeImports = interfaceType(l, pkgPath, interfaceName, typeArgs, q)
+	iface.Mismatches = mismatches
+	scaffolded := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		scaffolded[m.Name] = true
+		iface.Imports = mergeImports(iface.Imports, methodImports(m))
+	}
+	for _, w := range *q.warnings {
+		if w.Method == "" || scaffolded[w.Method] {
+			iface.Warnings = append(iface.Warnings, w)
+		}
+	}
+	return iface, nil
+}
+
+// interfaceType returns how the interface interfaceName of the package with
+// the given path, instantiated with typeArgs, is written in the package the
+// scaffolding is for, along with the import it needs. Aliases are kept.
+func interfaceType(l *loader, pkgPath, interfaceName string, typeArgs []ast.Expr, q *qualifier) (string, []Import) {
+	pkg, err := l.load(pkgPath, "")
+	if err != nil {
+		return interfaceName, nil
+	}
+	return q.record(func() string {
+		t := interfaceName
+		if qual := q.qualifyPath(pkg.ImportPath, pkg.Name); qual != "" {
+			t = qual + "." + t
+		}
+		if len(typeArgs) > 0 {
+			args := make([]string, len(typeArgs))
+			for i, arg := range typeArgs {
+				args[i] = types.ExprString(arg)
+			}
+			t += "[" + strings.Join(args, ", ") + "]"
+		}
+		return t
+	})
+}
+
+// findInterface type-checks the package with the given path, as imported by
+// a package in srcDir, and finds the declaration of its interface
+// interfaceName. Aliases are followed to the interface they stand for: if it
+// is an instance of a generic interface, inst holds its instantiated methods.
+func findInterface(l *loader, pkgPath, srcDir, interfaceName string) (pkg *typedPackage, typeSpec *ast.TypeSpec, inst *methodSet, err error) {
+	pkg, err = l.load(pkgPath, srcDir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	typeSpec, err = interfaceTypeSpec(interfaceName, pkg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if typeSpec.Assign.IsValid() {
+		if named, ok := types.Unalias(pkg.info.Defs[typeSpec.Name].Type()).(*types.Named); ok &&
+			named.Obj().Pkg() != nil && (named.Obj().Pkg() != pkg.types || named.Obj().Name() != interfaceName) {
+			dl("  %q is an alias of %s\n", interfaceName, named)
+			pkg, typeSpec, _, err = findInterface(l, named.Obj().Pkg().Path(), pkg.Dir, named.Obj().Name())
+			return pkg, typeSpec, instanceMethods(named), err
+		}
+	}
+	return pkg, typeSpec, nil, nil
+}
+
+// interfaceMethods returns the methods of the interface declared by
+// typeSpec in pkg, including the methods of the interfaces it embeds, in
+// the order they are declared. When inst is not nil, the methods it has
+// replace the generic methods declared. Types are qualified by q.
+func interfaceMethods(pkg *typedPackage, typeSpec *ast.TypeSpec, inst *methodSet, q *qualifier) ([]Method, error) {
+	q = q.from(pkg.types, pkg.fset, pkg.fileOf(typeSpec))
+	q.typeArgs = inst.typeArgs(pkg, typeSpec)
 	interfaceType, ok := typeSpec.Type.(*ast.InterfaceType)
 	if !ok {
 		return nil, NewNotAnInterfaceError("%q is not an interface", typeSpec.Name.Name)
@@ -227,27 +391,110 @@ func buildInterface(path string) (*Interface, error) {
 		dl("  %dth field with type %T and Names %v\n", i, field.Type, field.Names)
 		funcType, isMethod := field.Type.(*ast.FuncType)
 		if namesl := len(field.Names); namesl > 0 && isMethod {
-			methods = append(methods, buildMethod(field.Names[0].Name, funcType))
-		} else if ident, ok := field.Type.(*ast.Ident); ok {
-			dl("    embedded interface field %q\n", ident.Name)
-			embedded, err := buildInterface(fmt.Sprintf("%s.%s", pkgPath, ident.Name))
+			fn, _ := pkg.info.Defs[field.Names[0]].(*types.Func)
+			if instFn, ok := inst.method(field.Names[0].Name); ok {
+				fn = instFn
+			}
+			methods = appendMethods(methods, buildMethod(field.Names[0].Name, funcType, fn, q))
+		} else if embeddedPath, embeddedName, embeddedInst, ok := embeddedInterface(field.Type, pkg, q.file); ok {
+			dl("    embedded interface field %s.%s\n", embeddedPath, embeddedName)
+			if inst != nil {
+				embeddedInst = inst.embedded(embeddedPath, embeddedName)
+			}
+			embedded, err := embeddedMethods(pkg, embeddedPath, embeddedName, embeddedInst, q)
 			if err != nil {
-				dl("      error building embedded interface %q: %s\n", ident.Name, err.Error())
+				dl("      error building embedded interface %q: %s\n", embeddedName, err.Error())
 				return nil, err
 			}
-			dl("    adding %d methods from embedded interface\n", len(embedded.Methods))
-			for _, m := range embedded.Methods {
-				methods = append(methods, m)
-			}
+			dl("    adding %d methods from embedded interface\n", len(embedded))
+			methods = appendMethods(methods, embedded...)
 		} else {
-			dl("    unexpected field %q was not processed\n", ident.Name)
+			q.warn(field.Pos(), "element %s of interface %s is neither a method nor an embedded interface and was not scaffolded",
+				types.ExprString(field.Type), typeSpec.Name.Name)
 		}
 	}
-	methods, err = filterMethod(methods, methodName)
+	return methods, nil
+}
+
+// embeddedMethods returns the methods of the interface pkgPath.name embedded
+// in an interface of package from. See interfaceMethods.
+func embeddedMethods(from *typedPackage, pkgPath, name string, inst *methodSet, q *qualifier) ([]Method, error) {
+	if pkgPath == "" && name == "error" {
+		return []Method{errorMethod(q)}, nil
+	}
+	pkg, typeSpec, aliasInst, err := findInterface(from.loader, pkgPath, from.Dir, name)
 	if err != nil {
 		return nil, err
 	}
-	return NewInterface(methods), nil
+	if inst == nil {
+		inst = aliasInst
+	}
+	return interfaceMethods(pkg, typeSpec, inst, q)
+}
+
+// embeddedInterface returns the package path and name of the interface
+// embedded as expr in an interface of pkg declared in file, and whether
+// expr is an interface with methods at all. The path of the predeclared
+// interface error is "". If the embedded interface is an instance of a
+// generic interface, inst holds its instantiated methods.
+func embeddedInterface(expr ast.Expr, pkg *typedPackage, file *ast.File) (pkgPath, name string, inst *methodSet, ok bool) {
+	if named, ok := types.Unalias(pkg.info.TypeOf(expr)).(*types.Named); ok {
+		if named.Obj().Pkg() == nil { // only error has methods among the predeclared types
+			return "", named.Obj().Name(), nil, named.Obj().Name() == "error"
+		}
+		if _, isInterface := named.Underlying().(*types.Interface); !isInterface && isValidType(named.Underlying()) {
+			return "", "", nil, false
+		}
+		return named.Obj().Pkg().Path(), named.Obj().Name(), instanceMethods(named), true
+	}
+	if t := pkg.info.TypeOf(expr); t != nil && isValidType(t) {
+		return "", "", nil, false // a type that is not an interface (e.g., int)
+	}
+
+	// The type checker could not resolve it. Resolve it from the source.
+	switch expr := expr.(type) {
+	case *ast.IndexExpr:
+		return embeddedInterface(expr.X, pkg, file)
+	case *ast.IndexListExpr:
+		return embeddedInterface(expr.X, pkg, file)
+	case *ast.Ident:
+		return pkg.ImportPath, expr.Name, nil, true
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok {
+			if path, ok := fileImportPath(file, x.Name); ok {
+				return path, expr.Sel.Name, nil, true
+			}
+		}
+	}
+	return "", "", nil, false
+}
+
+// errorMethod returns the Error method of the predeclared interface error.
+func errorMethod(q *qualifier) Method {
+	fn := types.Universe.Lookup("error").Type().Underlying().(*types.Interface).Method(0)
+	sig := fn.Type().(*types.Signature)
+	result := sig.Results().At(0)
+	m := NewMethod(fn.Name(), []Parameter{}, []Parameter{
+		{Name: result.Name(), Type: q.typeString(result.Type()), Var: result},
+	})
+	m.Signature = sig
+	return m
+}
+
+// appendMethods appends to methods the given methods it does not have
+// already. An interface can embed several interfaces with the same method.
+func appendMethods(methods []Method, more ...Method) []Method {
+outer:
+	for _, m := range more {
+		for _, existing := range methods {
+			if existing.Name == m.Name {
+				dl("    skipping duplicate method %q\n", m.Name)
+				continue outer
+			}
+		}
+		methods = append(methods, m)
+	}
+	return methods
 }
 
 func filterMethod(ms []Method, methodName string) ([]Method, error) {
@@ -301,3 +548,22 @@ func renderInterface(i *Interface, receiver string, w io.Writer) error {
 	}
 	return nil
 }
+
+// renderTemplate executes tmpl with data, and writes the formatted source
+// it results in to w.
+func renderTemplate(tmpl *template.Template, data interface{}, w io.Writer) error {
+	var ugly bytes.Buffer
+	if err := tmpl.Execute(&ugly, data); err != nil {
+		return fmt.Errorf("error rendering template %q: %s\n", tmpl.Name(), err)
+	}
+	pretty, err := format.Source(ugly.Bytes())
+	if err != nil {
+		return fmt.Errorf("error formatting source:\n%s\n: %s\n",
+			ugly.Bytes(), err.Error())
+	}
+	_, err = w.Write(pretty)
+	if err != nil {
+		return fmt.Errorf("error writing the formatted source: %s\n", err)
+	}
+	return nil
+}
@@ -43,10 +43,14 @@ func TestParseImportInterface(t *testing.T) {
 		{"io.Reader", "io", "Reader", "", nil},
 		{"imports.Madeuper", "imports", "Madeuper", "", nil},
 		{"io..Reader", "io", "Reader", "", nil},
+		{"example.com/cache.Store[string, *models.User]", "example.com/cache", "Store[string, *models.User]", "", nil},
+		{"example.com/cache.Store[K, V]::Get", "example.com/cache", "Store[K, V]", "Get", nil},
+		{"cache.Store[string, map[string]int]::Get", "cache", "Store[string, map[string]int]", "Get", nil},
 
 		{"Reader", "", "", "", &InvalidImportFormatError{}},
 		{"", "", "", "", &InvalidImportFormatError{}},
 		{" 	\n", "", "", "", &InvalidImportFormatError{}},
+		{"cache.Store]string[", "", "", "", &InvalidImportFormatError{}},
 	}
 	for _, c := range cases {
 		gotPkg, gotInterface, gotMethod, gotErr := parseImport(c.in)
@@ -62,27 +66,6 @@ func TestParseImportInterface(t *testing.T) {
 	}
 }
 
-func TestBuildPackage(t *testing.T) {
-	cases := []struct {
-		in            string
-		wantedPkgName string
-		wantErr       error
-	}{
-		{"io", "io", nil},
-
-		{"nonexistent", "", &CouldNotFindPackageError{}},
-	}
-	for _, c := range cases {
-		gotPkg, gotErr := buildPackage(c.in)
-		if reflect.TypeOf(c.wantErr) != reflect.TypeOf(gotErr) {
-			t.Errorf("buildPackage(%q): wanted error type \"%T\", got \"%T\"", c.in, c.wantErr, gotErr)
-		} else if gotPkg.Name != c.wantedPkgName {
-			t.Errorf("buildPackage(%q) == (%q, %T), want (%q, %T)",
-				c.in, gotPkg.Name, gotErr, c.wantedPkgName, c.wantErr)
-		}
-	}
-}
-
 func TestInterfaceTypeSpec_FindsIt(t *testing.T) {
 	cases := []struct {
 		pkgPath       string
@@ -97,7 +80,7 @@ func TestInterfaceTypeSpec_FindsIt(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		pkg, err := buildPackage(c.pkgPath)
+		pkg, err := loaderFor("").load(c.pkgPath, "")
 		if err != nil {
 			t.Errorf("interfaceTypeSpec(...) failed precondition: could load package with path %q", c.pkgPath)
 		}
@@ -123,7 +106,7 @@ func TestInterfaceTypeSpec_ReportsItNicely(t *testing.T) {
 	wantErr := &InterfaceNotFoundError{}
 	fileNameWithError := "with_parse_errors.go"
 
-	pkg, err := buildPackage(pkgPath)
+	pkg, err := loaderFor("").load(pkgPath, "")
 	if err != nil {
 		t.Errorf("interfaceTypeSpec(...) failed precondition: could not load package with path %q", pkgPath)
 	}
@@ -212,38 +195,171 @@ func TestBuildInterface(t *testing.T) {
 			),
 			nil,
 		},
-		// TODO: make this case work! (embedding and interface of a different packages)
-		// {
-		// 	// embedded with an interface from another package
-		// 	"impl/impl/test_data/panther.ExternalEmbedded",
-		// 	NewInterface(
-		// 		[]Method{
-		// 			NewMethod(
-		// 				"Read",
-		// 				[]Parameter{NewParameter("p", "byte[]")},
-		// 				[]Parameter{NewParameter("n", "int"), NewParameter("err", "error")}),
-		// 			NewMethod(
-		// 				"Write",
-		// 				[]Parameter{NewParameter("p", "byte[]")},
-		// 				[]Parameter{NewParameter("n", "int"), NewParameter("err", "error")}),
-		// 		},
-		// 	),
-		// 	nil,
-		// },
+		{
+			// embedded with an interface from another package
+			"impl/impl/test_data/panther.ExternalEmbedded",
+			NewInterface(
+				[]Method{
+					NewMethod(
+						"Read",
+						[]Parameter{NewParameter("p", "[]byte")},
+						[]Parameter{NewParameter("n", "int"), NewParameter("err", "error")}),
+					NewMethod(
+						"Write",
+						[]Parameter{NewParameter("p", "[]byte")},
+						[]Parameter{NewParameter("n", "int"), NewParameter("err", "error")}),
+				},
+			),
+			nil,
+		},
+		{
+			// embedded with interfaces from an aliased import, overlapping
+			// methods and the predeclared error interface
+			"impl/impl/test_data/panther.AliasedEmbedded",
+			NewInterface(
+				[]Method{
+					NewMethod(
+						"Read",
+						[]Parameter{NewParameter("p", "[]byte")},
+						[]Parameter{NewParameter("n", "int"), NewParameter("err", "error")}),
+					NewMethod(
+						"Close",
+						[]Parameter{},
+						[]Parameter{NewParameter("", "error")}),
+					NewMethod(
+						"Error",
+						[]Parameter{},
+						[]Parameter{NewParameter("", "string")}),
+					NewMethod(
+						"Reset",
+						[]Parameter{NewParameter("r", "io.Reader")},
+						[]Parameter{}),
+				},
+			),
+			nil,
+		},
+		{
+			// embedded with an interface from a package that cannot be found
+			"impl/impl/test_data/panther.UnresolvedEmbedded",
+			nil,
+			&CouldNotFindPackageError{},
+		},
 	}
 
 	for _, c := range cases {
-		gotInterface, gotErr := buildInterface(c.interfacePath)
+		gotInterface, gotErr := buildInterface(c.interfacePath, &receiver{}, Options{})
 		if reflect.TypeOf(gotErr) != reflect.TypeOf(c.wantErr) {
 			t.Errorf(`buildInterface(%q): wanted error type "%T", got "%T": %q`,
 				c.interfacePath, c.wantErr, gotErr, gotErr.Error())
 		} else if c.wantErr != nil {
 			continue // The error match passed. Nothing more to test.
-		} else if !reflect.DeepEqual(gotInterface, c.wantInterface) {
+		} else if got := untyped(gotInterface); !reflect.DeepEqual(got, c.wantInterface) {
 			t.Errorf("buildInterface(%q)\ngot:\t%+v\nwanted:\t%+v",
-				c.interfacePath, gotInterface, c.wantInterface)
+				c.interfacePath, got, c.wantInterface)
+		}
+	}
+}
+
+func TestBuildInterface_IsTyped(t *testing.T) {
+	paths := []string{
+		"impl/impl/test_data/panther.Clawable",
+		"impl/impl/test_data/panther.Scenario",
+		"sort.Interface",
+		"io.ReadWriter",
+	}
+
+	for _, path := range paths {
+		iface, err := buildInterface(path, &receiver{}, Options{})
+		if err != nil {
+			t.Errorf("buildInterface(%q) failed precondition: %s", path, err)
+			continue
+		}
+		for _, m := range iface.Methods {
+			if m.Signature == nil {
+				t.Errorf("buildInterface(%q): method %q has no signature", path, m.Name)
+				continue
+			}
+			if got, want := len(m.In)+len(m.Out), m.Signature.Params().Len()+m.Signature.Results().Len(); got != want {
+				t.Errorf("buildInterface(%q): method %q has %d parameters, its signature has %d",
+					path, m.Name, got, want)
+			}
+			for _, p := range append(append([]Parameter{}, m.In...), m.Out...) {
+				if p.Var == nil || p.Var.Name() != p.Name {
+					t.Errorf("buildInterface(%q): parameter %q of method %q is not backed by its variable (%v)",
+						path, p.Name, m.Name, p.Var)
+				}
+			}
+		}
+	}
+}
+
+func TestBuildInterface_Imports(t *testing.T) {
+	path := "impl/impl/test_data/panther.WithStars::GetAccounts"
+	utils := Import{Path: "ultimatesoftware.com/accountstore/utils"}
+	models := Import{Path: "ultimatesoftware.com/accountstore/models"}
+
+	iface, err := buildInterface(path, &receiver{}, Options{})
+	if err != nil {
+		t.Fatalf("buildInterface(%q) failed precondition: %s", path, err)
+	}
+	m := iface.Methods[0]
+	if got := m.In[0].Imports; len(got) != 0 {
+		t.Errorf("buildInterface(%q): parameter %q imports %+v, wanted none", path, m.In[0].Name, got)
+	}
+	if got, want := m.In[1].Imports, []Import{utils}; !reflect.DeepEqual(got, want) {
+		t.Errorf("buildInterface(%q): parameter %q imports %+v, wanted %+v", path, m.In[1].Name, got, want)
+	}
+	if got, want := m.Out[0].Imports, []Import{models}; !reflect.DeepEqual(got, want) {
+		t.Errorf("buildInterface(%q): first result imports %+v, wanted %+v", path, got, want)
+	}
+	if got, want := iface.Imports, []Import{utils, models}; !reflect.DeepEqual(got, want) {
+		t.Errorf("buildInterface(%q) imports %+v, wanted %+v", path, got, want)
+	}
+}
+
+func TestBuildInterface_Type(t *testing.T) {
+	cases := []struct {
+		path        string
+		pkgPath     string
+		want        string
+		wantImports []Import
+	}{
+		{"io.Reader", "", "io.Reader", []Import{{Path: "io"}}},
+		{"impl/impl/test_data/panther.Clawable", "impl/impl/test_data/panther", "Clawable", []Import{}},
+		{"impl/impl/test_data/panther.Store[string, int]::Get", "", "panther.Store[string, int]",
+			[]Import{{Path: "impl/impl/test_data/panther"}}},
+		{"impl/impl/test_data/panther.ReaderStore", "", "panther.ReaderStore",
+			[]Import{{Path: "impl/impl/test_data/panther"}}},
+	}
+	for _, c := range cases {
+		iface, err := buildInterface(c.path, &receiver{}, Options{PkgPath: c.pkgPath})
+		if err != nil {
+			t.Errorf("buildInterface(%q) failed: %s", c.path, err)
+			continue
 		}
+		if iface.Type != c.want || !reflect.DeepEqual(iface.TypeImports, c.wantImports) {
+			t.Errorf("buildInterface(%q) is of type %q importing %+v, want %q importing %+v",
+				c.path, iface.Type, iface.TypeImports, c.want, c.wantImports)
+		}
+	}
+}
+
+// untyped returns a copy of i without the type-checked signatures and
+// variables, so that it can be compared with interfaces built by hand.
+func untyped(i *Interface) *Interface {
+	methods := make([]Method, len(i.Methods))
+	for im, m := range i.Methods {
+		methods[im] = NewMethod(m.Name, untypedParams(m.In), untypedParams(m.Out))
+	}
+	return NewInterface(methods)
+}
+
+func untypedParams(ps []Parameter) []Parameter {
+	params := make([]Parameter, len(ps))
+	for i, p := range ps {
+		params[i] = NewParameter(p.Name, p.Type)
 	}
+	return params
 }
 
 func TestRenderInterface(t *testing.T) {
@@ -6,10 +6,13 @@
 package impl
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 )
 
 // Impl is the main entry point for the impl package. It writes scaffolding
@@ -22,11 +25,236 @@ import (
 // It would be really helpful to look at the tests in impl_test.go for
 // more use cases.
 func Impl(path string, receiver string, w io.Writer) error {
-	iface, err := buildInterface(path)
+	_, err := ImplWithOptions(path, receiver, w, Options{})
+	return err
+}
+
+// Options configures the scaffolding written by ImplWithOptions.
+type Options struct {
+	// PkgPath is the import path of the package the scaffolding is
+	// written to. Types declared in that package are left unqualified,
+	// while types declared anywhere else (including the interface's own
+	// package) are qualified. When empty, every declared type is qualified.
+	PkgPath string
+
+	// Imports are the imports of the file the scaffolding is written to.
+	// Packages they import are referred to as the file already does, and
+	// their names are not used to refer to any other package.
+	Imports []Import
+
+	// Dir is the directory packages are resolved from, as the go command
+	// run in it would: honoring the go.mod and go.work files that apply to
+	// it, vendor directories, and GOFLAGS. It is usually the directory of
+	// the file the scaffolding is written to. When empty, packages are
+	// resolved from the current directory.
+	Dir string
+
+	// Missing makes ImplWithOptions write scaffolding only for the methods
+	// the receiver's type, declared in the package PkgPath, does not have
+	// yet: methods it has, whether declared or promoted from embedded
+	// fields, are left out. Methods it has with a different signature are
+	// left out too, and reported in Result.Mismatches.
+	Missing bool
+
+	// Strict makes ImplWithOptions fail with an *UnhandledConstructError,
+	// instead of writing scaffolding with warnings, when the interface has
+	// constructs that could not be handled.
+	Strict bool
+
+	// Mode is what ImplWithOptions writes for the interface. It writes
+	// stubs by default.
+	Mode Mode
+
+	// ReadOnly are the names of the methods of the interface that do not
+	// change the state of their implementation, when Mode is Mutex. Calls
+	// to them are passed on under the read lock of a sync.RWMutex, so that
+	// they can be made at the same time.
+	ReadOnly []string
+}
+
+// Mode is what ImplWithOptions writes for an interface.
+type Mode int
+
+const (
+	// Stub writes methods for the receiver that panic, to be implemented.
+	Stub Mode = iota
+	// Fake writes the receiver's type as a struct with a func field per
+	// method (e.g., ReadFunc for Read), and methods that call them.
+	Fake
+	// Mock writes the receiver's type as a mock that is told the calls it
+	// expects through a typed EXPECT builder (e.g., EXPECT().Read(...)),
+	// and fails on the calls it does not expect. Mocks are built on the
+	// package github.com/ajmesa9891/impl/mock.
+	Mock
+	// Spy writes the receiver's type as a spy that records the calls made
+	// to it, per method (e.g., ReadCalls for Read), and passes them on to an
+	// inner implementation of the interface, if any.
+	Spy
+	// Recorder writes the receiver's type as a recorder that, depending on
+	// its tape, passes the calls made to it on to an inner implementation
+	// of the interface and records them to a JSON golden file, or replays
+	// them from that file. Recorders are built on the package
+	// github.com/ajmesa9891/impl/replay.
+	Recorder
+	// Wrapper writes the receiver's type as a wrapper that holds an inner
+	// implementation of the interface and passes every call made to it on
+	// to it, for types that embed it to override only some of the methods.
+	Wrapper
+	// Logging writes the receiver's type as a decorator that logs the calls
+	// made to it with log/slog (their arguments, results, duration and
+	// error) and passes them on to an inner implementation of the
+	// interface.
+	Logging
+	// Metrics writes the receiver's type as a decorator that records the
+	// calls made to it, how long they took and whether they failed (their
+	// last result being a non-nil error) and passes them on to an inner
+	// implementation of the interface. Metrics decorators are built on the
+	// package github.com/ajmesa9891/impl/metrics.
+	Metrics
+	// Timeout writes the receiver's type as a decorator that passes the
+	// calls made to it on to an inner implementation of the interface,
+	// applying a timeout per method to the context the methods that take
+	// one as their first parameter, and returning the context's error
+	// instead when it is done.
+	Timeout
+	// Retry writes the receiver's type as a decorator that passes the calls
+	// made to it on to an inner implementation of the interface, and
+	// attempts again the calls to the methods whose last result is an error
+	// that fail, as a policy tells, until their first context.Context
+	// parameter, if any, is done. Retry decorators are built on the package
+	// github.com/ajmesa9891/impl/retry.
+	Retry
+	// Mutex writes the receiver's type as a decorator that passes the calls
+	// made to it on to an inner implementation of the interface one at a
+	// time, under the lock of a sync.Mutex, so that it is safe for
+	// concurrent use. With Options.ReadOnly, it holds a sync.RWMutex, and
+	// passes the calls to the read-only methods on under its read lock.
+	Mutex
+	// Multi writes the receiver's type as a decorator that passes the calls
+	// made to it on to each of several implementations of the interface in
+	// turn, like io.MultiWriter, and joins the errors they return. Its
+	// methods must return an error or nothing.
+	Multi
+	// Fallback writes the receiver's type as a decorator that passes the
+	// calls made to it on to a primary implementation of the interface and,
+	// when they fail (their last result being a non-nil error), on to each
+	// of several secondary ones in turn, until one of them succeeds.
+	Fallback
+)
+
+var modeNames = [...]string{
+	Stub:     "stub",
+	Fake:     "fake",
+	Mock:     "mock",
+	Spy:      "spy",
+	Recorder: "recorder",
+	Wrapper:  "wrapper",
+	Logging:  "logging",
+	Metrics:  "metrics",
+	Timeout:  "timeout",
+	Retry:    "retry",
+	Mutex:    "mutex",
+	Multi:    "multi",
+	Fallback: "fallback",
+}
+
+func (m Mode) String() string {
+	if m < 0 || int(m) >= len(modeNames) {
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+	return modeNames[m]
+}
+
+// ParseMode returns the mode named name (e.g., "fake" for Fake).
+func ParseMode(name string) (Mode, error) {
+	for m, modeName := range modeNames {
+		if modeName == name {
+			return Mode(m), nil
+		}
+	}
+	return 0, NewInvalidModeError("invalid mode %q: must be one of %s", name, strings.Join(modeNames[:], ", "))
+}
+
+// Result describes the scaffolding written by ImplWithOptions.
+type Result struct {
+	// Imports are the packages the scaffolding refers to, which the file
+	// it is written to has to import.
+	Imports []Import
+
+	// Warnings describe the constructs of the interface that could not be
+	// handled. The scaffolding may miss a method or may not compile when
+	// there are any.
+	Warnings []Warning
+
+	// Mismatches are the methods of the interface the receiver's type has
+	// with a different signature, when Options.Missing is set.
+	Mismatches []Mismatch
+}
+
+// ImplWithOptions is like Impl, but writes the scaffolding as configured
+// by opts and describes what it wrote.
+func ImplWithOptions(path string, receiver string, w io.Writer, opts Options) (*Result, error) {
+	recv, err := parseReceiver(receiver)
+	if err != nil {
+		return nil, err
+	}
+	err = recv.validate(opts.PkgPath, opts.Dir)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if opts.Mode != Stub && opts.Missing {
+		return nil, NewInvalidModeError("mode %s writes the receiver's type, so it cannot be missing methods", opts.Mode)
+	}
+	if opts.Mode != Mutex && len(opts.ReadOnly) > 0 {
+		return nil, NewInvalidModeError("mode %s has no read-only methods, only mode %s does", opts.Mode, Mutex)
+	}
+	iface, err := buildInterface(path, recv, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Rendering may add warnings (e.g., about arguments a recorder cannot
+	// record), so nothing is written until they are all known.
+	var buf bytes.Buffer
+	switch opts.Mode {
+	case Stub:
+		err = renderInterface(iface, receiver, &buf)
+	case Fake:
+		err = renderFake(iface, recv, &buf)
+	case Mock:
+		err = renderMock(iface, recv, &buf)
+	case Spy:
+		err = renderSpy(iface, recv, &buf)
+	case Recorder:
+		err = renderRecorder(iface, recv, &buf)
+	case Wrapper:
+		err = renderWrapper(iface, recv, &buf)
+	case Logging:
+		err = renderLogging(iface, recv, &buf)
+	case Metrics:
+		err = renderMetrics(iface, recv, &buf)
+	case Timeout:
+		err = renderTimeout(iface, recv, &buf)
+	case Retry:
+		err = renderRetry(iface, recv, &buf)
+	case Mutex:
+		err = renderMutex(iface, recv, opts.ReadOnly, &buf)
+	case Multi:
+		err = renderMulti(iface, recv, &buf)
+	case Fallback:
+		err = renderFallback(iface, recv, &buf)
+	default:
+		err = NewInvalidModeError("invalid mode %s", opts.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Strict && len(iface.Warnings) > 0 {
+		return nil, NewUnhandledConstructError(iface.Warnings)
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		return nil, err
 	}
-	return renderInterface(iface, receiver, w)
+	return &Result{Imports: iface.Imports, Warnings: iface.Warnings, Mismatches: iface.Mismatches}, nil
 }
 
 // debugL is the debug logger
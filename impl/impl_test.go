@@ -2,8 +2,19 @@ package impl
 
 import (
 	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+
+	"golang.org/x/tools/go/ast/astutil"
 )
 
 func TestImpl(t *testing.T) {
@@ -25,6 +36,20 @@ func (r *Repo) Puncture(strength int) {
 	panic("TODO: implement this method")
 }
 
+`,
+		},
+		{
+			"impl/impl/test_data/panther.ExternalEmbedded",
+			"rw *ReadWriter",
+			nil,
+			`func (rw *ReadWriter) Read(p []byte) (n int, err error) {
+	panic("TODO: implement this method")
+}
+
+func (rw *ReadWriter) Write(p []byte) (n int, err error) {
+	panic("TODO: implement this method")
+}
+
 `,
 		},
 		{
@@ -127,7 +152,7 @@ func () Write(p []byte) (n int, err error) {
 			"net/http.Handler",
 			"s Server",
 			nil,
-			`func (s Server) ServeHTTP(ResponseWriter, *Request) {
+			`func (s Server) ServeHTTP(http.ResponseWriter, *http.Request) {
 	panic("TODO: implement this method")
 }
 
@@ -155,7 +180,7 @@ func (src Source) Size() int64 {
 	panic("TODO: implement this method")
 }
 
-func (src Source) Mode() FileMode {
+func (src Source) Mode() fs.FileMode {
 	panic("TODO: implement this method")
 }
 
@@ -167,7 +192,7 @@ func (src Source) IsDir() bool {
 	panic("TODO: implement this method")
 }
 
-func (src Source) Sys() interface{} {
+func (src Source) Sys() any {
 	panic("TODO: implement this method")
 }
 
@@ -185,11 +210,15 @@ func (plt Platano) FieldAlign() int {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Method(int) Method {
+func (plt Platano) Method(int) reflect.Method {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) MethodByName(string) (Method, bool) {
+func (plt Platano) Methods() iter.Seq[reflect.Method] {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) MethodByName(string) (reflect.Method, bool) {
 	panic("TODO: implement this method")
 }
 
@@ -213,19 +242,19 @@ func (plt Platano) String() string {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Kind() Kind {
+func (plt Platano) Kind() reflect.Kind {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Implements(u Type) bool {
+func (plt Platano) Implements(u reflect.Type) bool {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) AssignableTo(u Type) bool {
+func (plt Platano) AssignableTo(u reflect.Type) bool {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) ConvertibleTo(u Type) bool {
+func (plt Platano) ConvertibleTo(u reflect.Type) bool {
 	panic("TODO: implement this method")
 }
 
@@ -237,7 +266,7 @@ func (plt Platano) Bits() int {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) ChanDir() ChanDir {
+func (plt Platano) ChanDir() reflect.ChanDir {
 	panic("TODO: implement this method")
 }
 
@@ -245,31 +274,39 @@ func (plt Platano) IsVariadic() bool {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Elem() Type {
+func (plt Platano) Elem() reflect.Type {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) Field(i int) reflect.StructField {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Field(i int) StructField {
+func (plt Platano) Fields() iter.Seq[reflect.StructField] {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) FieldByIndex(index []int) StructField {
+func (plt Platano) FieldByIndex(index []int) reflect.StructField {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) FieldByName(name string) (StructField, bool) {
+func (plt Platano) FieldByName(name string) (reflect.StructField, bool) {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) FieldByNameFunc(match func(string) bool) (StructField, bool) {
+func (plt Platano) FieldByNameFunc(match func(string) bool) (reflect.StructField, bool) {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) In(i int) Type {
+func (plt Platano) In(i int) reflect.Type {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Key() Type {
+func (plt Platano) Ins() iter.Seq[reflect.Type] {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) Key() reflect.Type {
 	panic("TODO: implement this method")
 }
 
@@ -289,15 +326,43 @@ func (plt Platano) NumOut() int {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Out(i int) Type {
+func (plt Platano) Out(i int) reflect.Type {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) Outs() iter.Seq[reflect.Type] {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) common() *rtype {
+func (plt Platano) OverflowComplex(x complex128) bool {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) uncommon() *uncommonType {
+func (plt Platano) OverflowFloat(x float64) bool {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) OverflowInt(x int64) bool {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) OverflowUint(x uint64) bool {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) CanSeq() bool {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) CanSeq2() bool {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) common() *abi.Type {
+	panic("TODO: implement this method")
+}
+
+func (plt Platano) uncommon() *reflect.uncommonType {
 	panic("TODO: implement this method")
 }
 
@@ -315,7 +380,7 @@ func (src Source) Size() int64 {
 	panic("TODO: implement this method")
 }
 
-func (src Source) Mode() FileMode {
+func (src Source) Mode() fs.FileMode {
 	panic("TODO: implement this method")
 }
 
@@ -327,7 +392,7 @@ func (src Source) IsDir() bool {
 	panic("TODO: implement this method")
 }
 
-func (src Source) Sys() interface{} {
+func (src Source) Sys() any {
 	panic("TODO: implement this method")
 }
 
@@ -345,11 +410,11 @@ func (plt Platano) FieldAlign() int {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Method(int) Method {
+func (plt Platano) Method(int) panther.Method {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) MethodByName(string) (Method, bool) {
+func (plt Platano) MethodByName(string) (panther.Method, bool) {
 	panic("TODO: implement this method")
 }
 
@@ -373,19 +438,19 @@ func (plt Platano) String() string {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Kind() Kind {
+func (plt Platano) Kind() panther.Kind {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Implements(u Type) bool {
+func (plt Platano) Implements(u panther.Type) bool {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) AssignableTo(u Type) bool {
+func (plt Platano) AssignableTo(u panther.Type) bool {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) ConvertibleTo(u Type) bool {
+func (plt Platano) ConvertibleTo(u panther.Type) bool {
 	panic("TODO: implement this method")
 }
 
@@ -397,7 +462,7 @@ func (plt Platano) Bits() int {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) ChanDir() ChanDir {
+func (plt Platano) ChanDir() panther.ChanDir {
 	panic("TODO: implement this method")
 }
 
@@ -405,31 +470,31 @@ func (plt Platano) IsVariadic() bool {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Elem() Type {
+func (plt Platano) Elem() panther.Type {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Field(i int) StructField {
+func (plt Platano) Field(i int) panther.StructField {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) FieldByIndex(index []int) StructField {
+func (plt Platano) FieldByIndex(index []int) panther.StructField {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) FieldByName(name string) (StructField, bool) {
+func (plt Platano) FieldByName(name string) (panther.StructField, bool) {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) FieldByNameFunc(match func(string) bool) (StructField, bool) {
+func (plt Platano) FieldByNameFunc(match func(string) bool) (panther.StructField, bool) {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) In(i int) Type {
+func (plt Platano) In(i int) panther.Type {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Key() Type {
+func (plt Platano) Key() panther.Type {
 	panic("TODO: implement this method")
 }
 
@@ -449,7 +514,35 @@ func (plt Platano) NumOut() int {
 	panic("TODO: implement this method")
 }
 
-func (plt Platano) Out(i int) Type {
+func (plt Platano) Out(i int) panther.Type {
+	panic("TODO: implement this method")
+}
+
+`,
+		},
+		{
+			"impl/impl/test_data/panther.WithEveryType",
+			"r *Repo",
+			nil,
+			`func (r *Repo) Compose(a [4]models.AccountSummary, b <-chan models.AccountSummary, c chan<- *models.AccountSummary) (interface{ Get() models.AccountSummary }, map[string][2]models.AccountSummary) {
+	panic("TODO: implement this method")
+}
+
+func (r *Repo) Visit(fn func(models.AccountSummary, int) (bool, error), s struct{ A models.AccountSummary }) *[]models.AccountSummary {
+	panic("TODO: implement this method")
+}
+
+`,
+		},
+		{
+			"impl/impl/test_data/panther.WithEveryResolvedType",
+			"r *Repo",
+			nil,
+			`func (r *Repo) Compose(a [4]byte, b <-chan io.Reader, c chan<- *io.PipeReader) (interface{ Read() io.Reader }, map[string][2]io.Reader) {
+	panic("TODO: implement this method")
+}
+
+func (r *Repo) Visit(fn func(io.Reader, int) (bool, error), s struct{ R io.Reader }) *[]io.Reader {
 	panic("TODO: implement this method")
 }
 
@@ -489,3 +582,584 @@ func (plt Platano) Out(i int) Type {
 		}
 	}
 }
+
+func TestImplWithOptions(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		opts          Options
+		wantErr       error
+		wantSource    string
+		wantImports   []Import
+	}{
+		{
+			"impl/impl/test_data/panther.Type::Elem",
+			"plt Platano",
+			Options{},
+			nil,
+			`func (plt Platano) Elem() panther.Type {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{{Path: "impl/impl/test_data/panther"}},
+		},
+		{
+			"impl/impl/test_data/panther.Type::Elem",
+			"plt Platano",
+			Options{PkgPath: "impl/impl/test_data/panther"},
+			nil,
+			`func (plt Platano) Elem() Type {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Type::FieldByName",
+			"plt Platano",
+			Options{PkgPath: "impl/impl/test_data/panther"},
+			nil,
+			`func (plt Platano) FieldByName(name string) (StructField, bool) {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+		{
+			"net/http.Handler",
+			"s Server",
+			Options{PkgPath: "net/http"},
+			nil,
+			`func (s Server) ServeHTTP(ResponseWriter, *Request) {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.WithAliases",
+			"r *Repo",
+			Options{PkgPath: "impl/impl/test_data/panther"},
+			nil,
+			`func (r *Repo) GetAccounts(tenantId string, opts *utils.QueryOpts) ([]models.AccountSummary, error) {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{
+				{Path: "ultimatesoftware.com/accountstore/utils"},
+				{Path: "ultimatesoftware.com/accountstore/models"},
+			},
+		},
+		{
+			"impl/impl/test_data/panther.Store",
+			"c *Cache[K, V]",
+			Options{},
+			nil,
+			`func (c *Cache[K, V]) Keys() []K {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache[K, V]) Each(fn func(K, V) bool) {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Store[string, *bytes.Buffer]",
+			"c *Cache",
+			Options{Imports: []Import{{Path: "bytes"}}},
+			nil,
+			`func (c *Cache) Keys() []string {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache) Get(key string) (*bytes.Buffer, bool) {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache) Set(key string, value *bytes.Buffer) {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache) Each(fn func(string, *bytes.Buffer) bool) {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{{Path: "bytes"}},
+		},
+		{
+			"impl/impl/test_data/panther.Store[int, Clawable]::Get",
+			"c *Cache",
+			Options{PkgPath: "impl/impl/test_data/panther"},
+			nil,
+			`func (c *Cache) Get(key int) (Clawable, bool) {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Pair[panther.Clawable]",
+			"p Pair",
+			Options{},
+			nil,
+			`func (p Pair) Swap(a panther.Clawable, b panther.Clawable) (panther.Clawable, panther.Clawable) {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{{Path: "impl/impl/test_data/panther"}},
+		},
+		{
+			"impl/impl/test_data/panther.ReaderStore::Get",
+			"c *Cache",
+			Options{},
+			nil,
+			`func (c *Cache) Get(key string) (io.Reader, bool) {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{{Path: "io"}},
+		},
+		{
+			"impl/impl/test_data/panther.Store[string]",
+			"c *Cache",
+			Options{},
+			&InvalidTypeArgumentsError{},
+			"",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Store[string, Unknown]",
+			"c *Cache",
+			Options{},
+			&InvalidTypeArgumentsError{},
+			"",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Clawable[string]",
+			"c *Cache",
+			Options{},
+			&InvalidTypeArgumentsError{},
+			"",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Store",
+			"c *Cache[A, B]",
+			Options{},
+			nil,
+			`func (c *Cache[A, B]) Keys() []A {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache[A, B]) Get(key A) (B, bool) {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache[A, B]) Set(key A, value B) {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache[A, B]) Each(fn func(A, B) bool) {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Boxes",
+			"c *Cache[A, B]",
+			Options{},
+			nil,
+			`func (c *Cache[A, B]) Unbox(b models.Box[B]) B {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache[A, B]) Box(key A) (models.Box[B], error) {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{{Path: "ultimatesoftware.com/accountstore/models"}},
+		},
+		{
+			"impl/impl/test_data/panther.Boxes[string, int]",
+			"c *Cache",
+			Options{},
+			nil,
+			`func (c *Cache) Unbox(b models.Box[int]) int {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache) Box(key string) (models.Box[int], error) {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{{Path: "ultimatesoftware.com/accountstore/models"}},
+		},
+		{
+			"impl/impl/test_data/panther.Boxes",
+			"c *Cache",
+			Options{},
+			nil,
+			`func (c *Cache) Unbox(b models.Box[V]) V {
+	panic("TODO: implement this method")
+}
+
+func (c *Cache) Box(key K) (models.Box[V], error) {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{{Path: "ultimatesoftware.com/accountstore/models"}},
+		},
+		{
+			"impl/impl/test_data/panther.Store[string, T]::Get",
+			"l *List[T]",
+			Options{PkgPath: "impl/impl/test_data/panther"},
+			nil,
+			`func (l *List[T]) Get(key string) (T, bool) {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Store",
+			"c *Cache[T]",
+			Options{},
+			&InvalidTypeArgumentsError{},
+			"",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Pair",
+			"l *List[A, B]",
+			Options{PkgPath: "impl/impl/test_data/panther"},
+			&InvalidReceiverError{},
+			"",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Clawable",
+			"l *List",
+			Options{PkgPath: "impl/impl/test_data/panther"},
+			&InvalidReceiverError{},
+			"",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Clawable",
+			"l *List[",
+			Options{},
+			&InvalidReceiverError{},
+			"",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.WithSameNamedPackages",
+			"c Converter",
+			Options{PkgPath: "impl/impl/test_data/panther"},
+			nil,
+			`func (c Converter) Convert(t *template.Template) *template2.Template {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]Import{{Path: "text/template"}, {Name: "template2", Path: "html/template"}},
+		},
+	}
+
+	for _, c := range cases {
+		var w bytes.Buffer
+		gotResult, gotErr := ImplWithOptions(c.interfacePath, c.receiver, &w, c.opts)
+		if reflect.TypeOf(gotErr) != reflect.TypeOf(c.wantErr) {
+			t.Errorf("ImplWithOptions(%q, %q, <writer>, %+v) == %T, wanted error: %T.\n%q",
+				c.interfacePath, c.receiver, c.opts, gotErr, c.wantErr, gotErr)
+		} else if c.wantErr != nil {
+			continue // got the error we wanted
+		} else if gotSrc := w.String(); c.wantSource != gotSrc {
+			t.Errorf("ImplWithOptions(%q, %q, <writer>, %+v) == \n\"%s\"\n, wanted: \n\"%s\"\n",
+				c.interfacePath, c.receiver, c.opts, gotSrc, c.wantSource)
+		} else if !reflect.DeepEqual(gotResult.Imports, c.wantImports) {
+			t.Errorf("ImplWithOptions(%q, %q, <writer>, %+v) imports %+v, wanted %+v",
+				c.interfacePath, c.receiver, c.opts, gotResult.Imports, c.wantImports)
+		}
+	}
+}
+
+func TestImplWithOptions_Warnings(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		opts          Options
+		wantErr       error
+		wantWarnings  []string // "<file>:<line> <method>"
+	}{
+		{
+			"impl/impl/test_data/panther.Clawable",
+			Options{Strict: true},
+			nil,
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.IntStringer",
+			Options{},
+			nil,
+			[]string{"generics.go:27 ", "generics.go:28 "},
+		},
+		{
+			"impl/impl/test_data/panther.IntStringer::String",
+			Options{},
+			nil,
+			[]string{"generics.go:27 ", "generics.go:28 "},
+		},
+		{
+			"impl/impl/test_data/panther.IntStringer",
+			Options{Strict: true},
+			&UnhandledConstructError{},
+			[]string{"generics.go:27 ", "generics.go:28 "},
+		},
+	}
+
+	for _, c := range cases {
+		var w bytes.Buffer
+		gotResult, gotErr := ImplWithOptions(c.interfacePath, "n Num", &w, c.opts)
+		if reflect.TypeOf(gotErr) != reflect.TypeOf(c.wantErr) {
+			t.Errorf("ImplWithOptions(%q, ..., %+v) == %T, wanted error: %T.\n%q",
+				c.interfacePath, c.opts, gotErr, c.wantErr, gotErr)
+			continue
+		}
+		var warnings []Warning
+		if gotErr != nil {
+			if w.Len() > 0 {
+				t.Errorf("ImplWithOptions(%q, ..., %+v) wrote scaffolding despite failing:\n%s",
+					c.interfacePath, c.opts, w.String())
+			}
+			warnings = gotErr.(*UnhandledConstructError).Warnings
+		} else {
+			warnings = gotResult.Warnings
+		}
+		var got []string
+		for _, warning := range warnings {
+			got = append(got, fmt.Sprintf("%s:%d %s",
+				filepath.Base(warning.Pos.Filename), warning.Pos.Line, warning.Method))
+		}
+		if !reflect.DeepEqual(got, c.wantWarnings) {
+			t.Errorf("ImplWithOptions(%q, ..., %+v) warned %q, wanted %q",
+				c.interfacePath, c.opts, got, c.wantWarnings)
+		}
+	}
+}
+
+func TestImplWithOptions_Missing(t *testing.T) {
+	cases := []struct {
+		interfacePath  string
+		receiver       string
+		wantSource     string
+		wantMismatches []string // "<method> <file>:<line> <have> != <want>"
+	}{
+		{
+			"impl/impl/test_data/panther.ClawReadWriter",
+			"h *HalfClaw",
+			`func (h *HalfClaw) Puncture(strength int) {
+	panic("TODO: implement this method")
+}
+
+`,
+			[]string{
+				"Read implementers.go:23 func(p []byte) int != func(p []byte) (n int, err error)",
+				"Write implementers.go:20 field func(p []byte) (n int, err error) != func(p []byte) (n int, err error)",
+			},
+		},
+		{
+			"impl/impl/test_data/panther.ClawReadWriter::Puncture",
+			"h *HalfClaw",
+			`func (h *HalfClaw) Puncture(strength int) {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.ClawReadWriter",
+			"f FullClaw",
+			"",
+			nil,
+		},
+		{
+			"impl/impl/test_data/panther.Clawable",
+			"n *NewClaw",
+			`func (n *NewClaw) Hardness() int {
+	panic("TODO: implement this method")
+}
+
+func (n *NewClaw) Puncture(strength int) {
+	panic("TODO: implement this method")
+}
+
+`,
+			nil,
+		},
+	}
+
+	opts := Options{PkgPath: "impl/impl/test_data/panther", Missing: true}
+	for _, c := range cases {
+		var w bytes.Buffer
+		gotResult, gotErr := ImplWithOptions(c.interfacePath, c.receiver, &w, opts)
+		if gotErr != nil {
+			t.Errorf("ImplWithOptions(%q, %q, ...) failed: %s", c.interfacePath, c.receiver, gotErr)
+			continue
+		}
+		if gotSrc := w.String(); gotSrc != c.wantSource {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == \n\"%s\"\n, wanted: \n\"%s\"\n",
+				c.interfacePath, c.receiver, gotSrc, c.wantSource)
+		}
+		var got []string
+		for _, m := range gotResult.Mismatches {
+			got = append(got, fmt.Sprintf("%s %s:%d %s != %s",
+				m.Method.Name, filepath.Base(m.Pos.Filename), m.Pos.Line, m.Have, m.Want))
+		}
+		if !reflect.DeepEqual(got, c.wantMismatches) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) mismatches %q, wanted %q",
+				c.interfacePath, c.receiver, got, c.wantMismatches)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for m := range modeNames {
+		got, err := ParseMode(Mode(m).String())
+		if err != nil || got != Mode(m) {
+			t.Errorf("ParseMode(%q) == %v, %v, want %v", Mode(m).String(), got, err, Mode(m))
+		}
+	}
+	if _, err := ParseMode("fakes"); err == nil {
+		t.Errorf("ParseMode(%q) succeeded, want an error", "fakes")
+	}
+}
+
+// TestImplWithOptions_WrittenTestFiles checks that the decorators the tests
+// of the packages they are built on keep as written by goimpl are still
+// what it writes. Each file declares the types its decorators are written
+// for before them.
+func TestImplWithOptions_WrittenTestFiles(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOTOOLCHAIN", "local")
+
+	cases := []struct {
+		file       string // relative to the repository's root
+		mode       Mode
+		interfaces []string // relative to the file's package, unless qualified
+		receivers  []string
+	}{
+		{"mock/mocks_test.go", Mock, []string{"io.ReadWriter", "Roarer"}, []string{"m *MockReadWriter", "m *MockRoarer"}},
+		{"replay/recorders_test.go", Recorder, []string{"io.ReadWriter", "Roarer"}, []string{"r *RecordReadWriter", "r *RecordRoarer"}},
+		{"metrics/decorators_test.go", Metrics, []string{"io.ReadWriter", "Roarer"}, []string{"m *MetricsReadWriter", "m *MetricsRoarer"}},
+		{"retry/decorators_test.go", Retry, []string{"Fetcher"}, []string{"r *RetryFetcher"}},
+	}
+	for _, c := range cases {
+		src, err := os.ReadFile(filepath.Join("..", filepath.FromSlash(c.file)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixtures, written, err := splitWrittenFile(src, c.receivers[0])
+		if err != nil {
+			t.Errorf("%s: %s", c.file, err)
+			continue
+		}
+		root := t.TempDir()
+		writeTree(t, root, map[string]string{
+			"go.mod":      "module example.com/fixtures\n\ngo 1.21\n",
+			"fixtures.go": fixtures,
+		})
+
+		var got []string
+		for i, iface := range c.interfaces {
+			if !strings.Contains(iface, ".") {
+				iface = "example.com/fixtures." + iface
+			}
+			var w bytes.Buffer
+			_, err := ImplWithOptions(iface, c.receivers[i], &w, Options{Dir: root, PkgPath: "example.com/fixtures", Mode: c.mode})
+			if err != nil {
+				t.Fatalf("%s: ImplWithOptions(%q, %q, ...) failed: %s", c.file, iface, c.receivers[i], err)
+			}
+			got = append(got, strings.TrimSpace(w.String()))
+		}
+		if got, want := strings.Join(got, "\n\n"), strings.TrimSpace(written); got != want {
+			t.Errorf("%s keeps\n%s\nbut goimpl -mode=%s now writes\n%s", c.file, want, c.mode, got)
+		}
+	}
+}
+
+// splitWrittenFile splits src, the source of a test file, into the source of
+// a package declaring the types it declares before the type of receiver,
+// and the source from the declaration of that type on.
+func splitWrittenFile(src []byte, receiver string) (fixtures, written string, err error) {
+	typeName := strings.TrimPrefix(strings.Fields(receiver)[1], "*")
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return "", "", err
+	}
+	start := -1
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE || gen.Specs[0].(*ast.TypeSpec).Name.Name != typeName {
+			continue
+		}
+		start = fset.Position(gen.Pos()).Offset
+		if gen.Doc != nil {
+			start = fset.Position(gen.Doc.Pos()).Offset
+		}
+		break
+	}
+	if start < 0 {
+		return "", "", fmt.Errorf("type %s is not declared", typeName)
+	}
+
+	// The fixtures are a package of their own, which only imports what its
+	// types need.
+	fset = token.NewFileSet()
+	file, err = parser.ParseFile(fset, "", src[:start], parser.ParseComments)
+	if err != nil {
+		return "", "", err
+	}
+	file.Name.Name = "fixtures"
+	for _, spec := range append([]*ast.ImportSpec{}, file.Imports...) {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if !astutil.UsesImport(file, path) {
+			astutil.DeleteImport(fset, file, path)
+		}
+	}
+	var w bytes.Buffer
+	if err := format.Node(&w, fset, file); err != nil {
+		return "", "", err
+	}
+	return w.String(), string(src[start:]), nil
+}
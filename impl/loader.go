@@ -0,0 +1,163 @@
+package impl
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sync"
+)
+
+// loader loads packages as the go command does when run in a directory:
+// honoring the go.mod and go.work files that apply to it, vendor
+// directories, and GOFLAGS. Packages are type-checked from source, and
+// their dependencies are imported the same way. Each dependency is only
+// type-checked once.
+type loader struct {
+	sync.Mutex
+	ctxt build.Context
+	fset *token.FileSet
+
+	// imported are the dependencies type-checked so far, by directory. A
+	// nil package is being type-checked.
+	imported map[string]*types.Package
+}
+
+// loaders are the loaders created so far, by directory.
+var loaders = struct {
+	sync.Mutex
+	m map[string]*loader
+}{m: make(map[string]*loader)}
+
+// loaderFor returns the loader for packages resolved from dir, or from the
+// current directory when dir is empty.
+func loaderFor(dir string) *loader {
+	if dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+	loaders.Lock()
+	defer loaders.Unlock()
+	l, ok := loaders.m[dir]
+	if !ok {
+		l = &loader{
+			ctxt:     build.Default,
+			fset:     token.NewFileSet(),
+			imported: make(map[string]*types.Package),
+		}
+		l.ctxt.Dir = dir
+		loaders.m[dir] = l
+	}
+	return l
+}
+
+// buildPackage finds the package with the given import path, as imported
+// by a package in srcDir. An empty srcDir is the directory of l.
+func (l *loader) buildPackage(pkgPath, srcDir string) (pkg *build.Package, err error) {
+	if srcDir == "" {
+		srcDir = l.ctxt.Dir
+	}
+	pkg, err = l.ctxt.Import(pkgPath, srcDir, 0)
+	if err != nil {
+		err = NewCouldNotFindPackageError("could not find interface's package (%q): %s", pkgPath, err)
+	}
+	return
+}
+
+// typedPackage is a package that has been parsed and type-checked.
+type typedPackage struct {
+	*build.Package
+	loader   *loader
+	fset     *token.FileSet
+	files    []*ast.File
+	unparsed []string
+	types    *types.Package
+	info     *types.Info
+}
+
+// load finds the package with the given import path, as imported by a
+// package in srcDir, and type-checks it. See checkPackage.
+func (l *loader) load(pkgPath, srcDir string) (*typedPackage, error) {
+	bpkg, err := l.buildPackage(pkgPath, srcDir)
+	if err != nil {
+		return nil, err
+	}
+	return l.checkPackage(bpkg), nil
+}
+
+// checkPackage parses and type-checks the files of the given package. Type
+// errors (e.g., imports that cannot be resolved) do not stop the check: the
+// types affected are left invalid and are later printed from their source.
+func (l *loader) checkPackage(pkg *build.Package) *typedPackage {
+	l.Lock()
+	defer l.Unlock()
+
+	tp := &typedPackage{Package: pkg, loader: l, fset: l.fset}
+	tp.files, tp.unparsed = l.parseFiles(pkg)
+	tp.info = &types.Info{
+		Defs:  make(map[*ast.Ident]types.Object),
+		Types: make(map[ast.Expr]types.TypeAndValue),
+	}
+	tp.types, _ = l.config(pkg).Check(pkg.ImportPath, tp.fset, tp.files, tp.info)
+	return tp
+}
+
+// parseFiles parses the files of pkg, and returns the names of the files that
+// could not be parsed.
+func (l *loader) parseFiles(pkg *build.Package) (files []*ast.File, unparsed []string) {
+	for _, fileName := range append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...) {
+		file, err := parser.ParseFile(l.fset, filepath.Join(pkg.Dir, fileName), nil, 0)
+		if err != nil {
+			unparsed = append(unparsed, fileName)
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, unparsed
+}
+
+// config returns the configuration pkg is type-checked with. It must be used
+// with the lock held.
+func (l *loader) config(pkg *build.Package) *types.Config {
+	return &types.Config{
+		Importer:         l,
+		FakeImportC:      true,
+		IgnoreFuncBodies: true,
+		Error: func(err error) {
+			dl("    type error in package %q: %s", pkg.ImportPath, err)
+		},
+	}
+}
+
+// Import implements types.Importer. It must be called with the lock held.
+func (l *loader) Import(path string) (*types.Package, error) {
+	return l.ImportFrom(path, l.ctxt.Dir, 0)
+}
+
+// ImportFrom implements types.ImporterFrom. It must be called with the lock
+// held. Packages with type errors are imported as far as they could be
+// type-checked.
+func (l *loader) ImportFrom(path, srcDir string, _ types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	bpkg, err := l.ctxt.Import(path, srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if pkg, ok := l.imported[bpkg.Dir]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through package %q", bpkg.ImportPath)
+		}
+		return pkg, nil
+	}
+	l.imported[bpkg.Dir] = nil
+	files, _ := l.parseFiles(bpkg)
+	pkg, _ := l.config(bpkg).Check(bpkg.ImportPath, l.fset, files, nil)
+	l.imported[bpkg.Dir] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,139 @@
+package impl
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildPackage(t *testing.T) {
+	cases := []struct {
+		in            string
+		wantedPkgName string
+		wantErr       error
+	}{
+		{"io", "io", nil},
+
+		{"nonexistent", "", &CouldNotFindPackageError{}},
+	}
+	for _, c := range cases {
+		gotPkg, gotErr := loaderFor("").buildPackage(c.in, "")
+		if reflect.TypeOf(c.wantErr) != reflect.TypeOf(gotErr) {
+			t.Errorf("buildPackage(%q): wanted error type \"%T\", got \"%T\"", c.in, c.wantErr, gotErr)
+		} else if gotPkg.Name != c.wantedPkgName {
+			t.Errorf("buildPackage(%q) == (%q, %T), want (%q, %T)",
+				c.in, gotPkg.Name, gotErr, c.wantedPkgName, c.wantErr)
+		}
+	}
+}
+
+// writeTree writes the given files, by slash-separated path, under dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// shapesModule is a module with a package whose name differs from the last
+// element of its import path, so the import it needs is only known when the
+// package is found.
+var shapesModule = map[string]string{
+	"shapes/go.mod": "module example.com/shapes\n\ngo 1.21\n",
+	"shapes/shapes.go": `package shapes
+
+import "example.com/shapes/unitsv1"
+
+type Shape interface {
+	Area() units.Meters
+}
+`,
+	"shapes/unitsv1/units.go": "package units\n\ntype Meters float64\n",
+}
+
+func TestImplWithOptions_Modules(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOTOOLCHAIN", "local")
+
+	cases := []struct {
+		name  string
+		files map[string]string
+		setup func(t *testing.T, root string)
+	}{
+		{
+			"replace directive",
+			map[string]string{
+				"app/go.mod": "module example.com/app\n\ngo 1.21\n\nrequire example.com/shapes v0.0.0\n\n" +
+					"replace example.com/shapes => ../shapes\n",
+			},
+			nil,
+		},
+		{
+			"workspace",
+			map[string]string{
+				"go.work":    "go 1.21\n\nuse (\n\t./app\n\t./shapes\n)\n",
+				"app/go.mod": "module example.com/app\n\ngo 1.21\n",
+			},
+			nil,
+		},
+		{
+			"vendor directory",
+			map[string]string{
+				"app/go.mod": "module example.com/app\n\ngo 1.21\n\nrequire example.com/shapes v0.0.0\n\n" +
+					"replace example.com/shapes => ../shapes\n",
+				"app/deps.go": "package app\n\nimport _ \"example.com/shapes\"\n",
+			},
+			func(t *testing.T, root string) {
+				cmd := exec.Command("go", "mod", "vendor")
+				cmd.Dir = filepath.Join(root, "app")
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("go mod vendor: %s\n%s", err, out)
+				}
+				// Only the vendored copy is left to be found.
+				if err := os.RemoveAll(filepath.Join(root, "shapes")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	want := `func (s Square) Area() units.Meters {
+	panic("TODO: implement this method")
+}
+
+`
+	for _, c := range cases {
+		root := t.TempDir()
+		writeTree(t, root, shapesModule)
+		writeTree(t, root, c.files)
+		writeTree(t, root, map[string]string{"app/app.go": "package app\n"})
+		if c.setup != nil {
+			c.setup(t, root)
+		}
+
+		var w bytes.Buffer
+		opts := Options{Dir: filepath.Join(root, "app"), PkgPath: "example.com/app"}
+		result, err := ImplWithOptions("example.com/shapes.Shape", "s Square", &w, opts)
+		if err != nil {
+			t.Errorf("%s: ImplWithOptions(...) failed: %s", c.name, err)
+			continue
+		}
+		if got := w.String(); got != want {
+			t.Errorf("%s: ImplWithOptions(...) == \n\"%s\"\n, wanted: \n\"%s\"\n", c.name, got, want)
+		}
+		wantImports := []Import{{Path: "example.com/shapes/unitsv1"}}
+		if !reflect.DeepEqual(result.Imports, wantImports) {
+			t.Errorf("%s: ImplWithOptions(...) imports %+v, wanted %+v", c.name, result.Imports, wantImports)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package impl
+
+import (
+	"io"
+	"text/template"
+)
+
+// loggingMethod is a method of a logging decorator.
+type loggingMethod struct {
+	forwardedMethod
+	Start         string   // the name of the method's variable holding when it was called
+	CtxArg        string   // the context logged with (e.g., "ctx" or "context.Background()")
+	ErrArg        string   // the error logged (e.g., "err" or "nil")
+	LoggedArgs    []loggedValue // the parameters logged, the context aside
+	LoggedResults []loggedValue // the results logged, the error aside
+}
+
+// loggedValue is a parameter or result of a method of a logging decorator.
+type loggedValue struct {
+	Key string // what it is logged as: its name in the interface, if any
+	Var string // the name of its variable
+}
+
+var loggingTmpl = template.Must(template.New("logging").Parse(
+	"// {{.Type}} logs the calls made to it to Logger, or to the default logger\n" +
+		"// if it is nil, and passes them on to Inner.\n" +
+		"type {{.Type}} struct {\n" +
+		"Inner  {{.InnerType}}\n" +
+		"Logger *{{.Slog}}.Logger\n\n" +
+		"// Redact, unless it is nil, returns what is logged for the argument arg\n" +
+		"// of the parameter named param of method, so that sensitive arguments\n" +
+		"// can be left out (e.g., by returning \"REDACTED\" for passwords).\n" +
+		"Redact func(method, param string, arg interface{}) interface{}\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"{{$method := .Name}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{.Start}} := {{$.Time}}.Now()\n" +
+		"{{if .Out}}{{.Results}} = {{end}}{{$.Recv}}.Inner.{{.Name}}({{.Args}})\n" +
+		"{{$.Recv}}.log({{.CtxArg}}, \"{{.Name}}\", {{.Start}}, {{.ErrArg}}" +
+		"{{if .LoggedArgs}}, {{$.Slog}}.Group(\"args\"" +
+		"{{range .LoggedArgs}}, \"{{.Key}}\", {{$.Recv}}.redact(\"{{$method}}\", \"{{.Key}}\", {{.Var}}){{end}}){{end}}" +
+		"{{if .LoggedResults}}, {{$.Slog}}.Group(\"results\"{{range .LoggedResults}}, \"{{.Key}}\", {{.Var}}{{end}}){{end}})\n" +
+		"{{if .Out}}return\n{{end}}" +
+		"}\n\n" +
+		"{{end}}" +
+		"// log logs the call to method made at start, which failed with err unless\n" +
+		"// it is nil, along with attrs.\n" +
+		"func ({{.Recv}} {{.Receiver}}) log(ctx {{.Context}}.Context, method string, start {{.Time}}.Time, err error, attrs ...{{.Slog}}.Attr) {\n" +
+		"attrs = append(attrs, {{.Slog}}.Duration(\"duration\", {{.Time}}.Since(start)))\n" +
+		"level := {{.Slog}}.LevelInfo\n" +
+		"if err != nil {\n" +
+		"level = {{.Slog}}.LevelError\n" +
+		"attrs = append(attrs, {{.Slog}}.Any(\"error\", err))\n" +
+		"}\n" +
+		"logger := {{.Recv}}.Logger\n" +
+		"if logger == nil {\n" +
+		"logger = {{.Slog}}.Default()\n" +
+		"}\n" +
+		"logger.LogAttrs(ctx, level, method, attrs...)\n" +
+		"}\n\n" +
+		"// redact returns what is logged for the argument arg of the parameter\n" +
+		"// named param of method.\n" +
+		"func ({{.Recv}} {{.Receiver}}) redact(method, param string, arg interface{}) interface{} {\n" +
+		"if {{.Recv}}.Redact == nil {\n" +
+		"return arg\n" +
+		"}\n" +
+		"return {{.Recv}}.Redact(method, param, arg)\n" +
+		"}\n"))
+
+// renderLogging writes the declaration of the receiver's type as a logging
+// decorator of the interface i, which logs the calls made to it with
+// log/slog and passes them on to an inner implementation, along with its
+// methods. The calls are logged with the method's name, its arguments but
+// its context, its results but its error, how long it took and the error, if
+// any, at level Error if there is one and Info otherwise.
+func renderLogging(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Logging); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to log", i.Name)
+	}
+	if err := checkMembers(i, Logging, "Inner", "Logger", "Redact", "log", "redact"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		InnerType string
+		Slog      string
+		Time      string
+		Context   string
+		Methods   []loggingMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		InnerType: i.useType(),
+		Slog:      i.importName("log/slog", "slog"),
+		Time:      i.importName("time", "time"),
+		Context:   i.importName("context", "context"),
+	}
+	for j, m := range forwardedMethods(i, recvName, data.Slog, data.Time, data.Context) {
+		lm := loggingMethod{
+			forwardedMethod: m,
+			Start:           freeName("start", m.taken),
+			CtxArg:          m.Ctx,
+			ErrArg:          m.Err,
+		}
+		if lm.CtxArg == "" {
+			lm.CtxArg = data.Context + ".Background()"
+		}
+		if lm.ErrArg == "" {
+			lm.ErrArg = "nil"
+		}
+		for k, p := range m.In {
+			if p.Name != m.Ctx {
+				lm.LoggedArgs = append(lm.LoggedArgs, newLoggedValue(i.Methods[j].In[k], p))
+			}
+		}
+		for k, p := range m.Out {
+			if p.Name != m.Err {
+				lm.LoggedResults = append(lm.LoggedResults, newLoggedValue(i.Methods[j].Out[k], p))
+			}
+		}
+		data.Methods = append(data.Methods, lm)
+	}
+	return renderTemplate(loggingTmpl, data, w)
+}
+
+// newLoggedValue returns how the parameter or result declared as declared
+// in the interface, and named as named in the decorator, is logged.
+func newLoggedValue(declared, named Parameter) loggedValue {
+	v := loggedValue{Key: declared.Name, Var: named.Name}
+	if v.Key == "" || v.Key == "_" {
+		v.Key = v.Var
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Logging(t *testing.T) {
+	var w bytes.Buffer
+	result, err := ImplWithOptions("io.ReadWriter::Write", "l *LoggingWriter", &w, Options{Mode: Logging})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := `// LoggingWriter logs the calls made to it to Logger, or to the default logger
+// if it is nil, and passes them on to Inner.
+type LoggingWriter struct {
+	Inner  io.ReadWriter
+	Logger *slog.Logger
+
+	// Redact, unless it is nil, returns what is logged for the argument arg
+	// of the parameter named param of method, so that sensitive arguments
+	// can be left out (e.g., by returning "REDACTED" for passwords).
+	Redact func(method, param string, arg interface{}) interface{}
+}
+
+func (l *LoggingWriter) Write(p []byte) (n int, err error) {
+	start := time.Now()
+	n, err = l.Inner.Write(p)
+	l.log(context.Background(), "Write", start, err, slog.Group("args", "p", l.redact("Write", "p", p)), slog.Group("results", "n", n))
+	return
+}
+
+// log logs the call to method made at start, which failed with err unless
+// it is nil, along with attrs.
+func (l *LoggingWriter) log(ctx context.Context, method string, start time.Time, err error, attrs ...slog.Attr) {
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.LogAttrs(ctx, level, method, attrs...)
+}
+
+// redact returns what is logged for the argument arg of the parameter
+// named param of method.
+func (l *LoggingWriter) redact(method, param string, arg interface{}) interface{} {
+	if l.Redact == nil {
+		return arg
+	}
+	return l.Redact(method, param, arg)
+}
+`
+	if got := w.String(); got != want {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwant\n%s", got, want)
+	}
+	wantImports := []Import{{Path: "io"}, {Path: "log/slog"}, {Path: "time"}, {Path: "context"}}
+	if !reflect.DeepEqual(result.Imports, wantImports) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, wantImports)
+	}
+}
+
+func TestImplWithOptions_LoggingUsesContexts(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.WithUnnamed", "l *LoggingRoarer", &w, Options{Mode: Logging})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"\tl.log(p0, \"Roar\", start, r0, slog.Group(\"args\", \"p1\", l.redact(\"Roar\", \"p1\", p1), \"p2\", l.redact(\"Roar\", \"p2\", p2)))\n",
+		"\tl.Inner.Purr(p0, loudness)\n" +
+			"\tl.log(context.Background(), \"Purr\", start, nil, slog.Group(\"args\", \"p0\", l.redact(\"Purr\", \"p0\", p0), \"loudness\", l.redact(\"Purr\", \"loudness\", loudness)))\n",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_LoggingRenamesShadowingParameters(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Shadower", "l *LoggingShadower", &w, Options{Mode: Logging})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"func (l *LoggingShadower) At(ctx context.Context, time_ time.Time) (r0 int, r1 error) {\n" +
+			"\tstart := time.Now()\n" +
+			"\tr0, r1 = l.Inner.At(ctx, time_)\n" +
+			"\tl.log(ctx, \"At\", start, r1, slog.Group(\"args\", \"time\", l.redact(\"At\", \"time\", time_)), slog.Group(\"results\", \"r0\", r0))\n",
+		"\tl.log(context.Background(), \"Fetch\", start, r0, slog.Group(\"args\", \"context\", l.redact(\"Fetch\", \"context\", context_)))\n",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_LoggingFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "l *LoggingReader[T]", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.Layered", "l *LoggingLayered", &InvalidInterfacePathError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Logging})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package impl
+
+import (
+	"io"
+	"text/template"
+)
+
+// metricsPath is the import path of the package metrics decorators are built
+// on.
+const metricsPath = "github.com/ajmesa9891/impl/metrics"
+
+// metricsMethod is a method of a metrics decorator.
+type metricsMethod struct {
+	forwardedMethod
+	Start  string // the name of the method's variable holding when it was called
+	ErrArg string // the error recorded (e.g., "err" or "nil")
+}
+
+var metricsTmpl = template.Must(template.New("metrics").Parse(
+	"// {{.Type}} records the calls made to it to Recorder, which must not be\n" +
+		"// nil, and passes them on to Inner.\n" +
+		"type {{.Type}} struct {\n" +
+		"Inner    {{.InnerType}}\n" +
+		"Recorder {{.Pkg}}.Recorder\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{.Start}} := {{$.Time}}.Now()\n" +
+		"{{if .Out}}{{.Results}} = {{end}}{{$.Recv}}.Inner.{{.Name}}({{.Args}})\n" +
+		"{{$.Recv}}.Recorder.Record(\"{{.Name}}\", {{$.Time}}.Since({{.Start}}), {{.ErrArg}})\n" +
+		"{{if .Out}}return\n{{end}}" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderMetrics writes the declaration of the receiver's type as a metrics
+// decorator of the interface i, which records the calls made to it, how
+// long they took and whether they failed, and passes them on to an inner
+// implementation, along with its methods. Calls fail when the method's last
+// result is an error that is not nil. Metrics decorators are built on the
+// package at metricsPath.
+func renderMetrics(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Metrics); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to record", i.Name)
+	}
+	if err := checkMembers(i, Metrics, "Inner", "Recorder"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		InnerType string
+		Pkg       string
+		Time      string
+		Methods   []metricsMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		InnerType: i.useType(),
+		Pkg:       i.importName(metricsPath, "metrics"),
+		Time:      i.importName("time", "time"),
+	}
+	for _, m := range forwardedMethods(i, recvName, data.Pkg, data.Time) {
+		mm := metricsMethod{forwardedMethod: m, Start: freeName("start", m.taken), ErrArg: m.Err}
+		if mm.ErrArg == "" {
+			mm.ErrArg = "nil"
+		}
+		data.Methods = append(data.Methods, mm)
+	}
+	return renderTemplate(metricsTmpl, data, w)
+}
@@ -0,0 +1,77 @@
+package impl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Metrics(t *testing.T) {
+	// The decorator the metrics package is tested with was written by Metrics.
+	decorators, err := ioutil.ReadFile("../metrics/decorators_test.go")
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed precondition: %s", err)
+	}
+
+	var w bytes.Buffer
+	result, err := ImplWithOptions("io.ReadWriter", "m *MetricsReadWriter", &w, Options{Mode: Metrics})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	if !strings.Contains(string(decorators), w.String()) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich is not in ../metrics/decorators_test.go", w.String())
+	}
+	if want := []Import{{Path: "io"}, {Path: metricsPath}, {Path: "time"}}; !reflect.DeepEqual(result.Imports, want) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, want)
+	}
+}
+
+func TestImplWithOptions_MetricsDetectsFailures(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.WithUnnamed", "m *MetricsRoarer", &w, Options{Mode: Metrics})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"\tr0 = m.Inner.Roar(p0, p1, p2...)\n\tm.Recorder.Record(\"Roar\", time.Since(start), r0)\n",
+		"\tm.Inner.Purr(p0, loudness)\n\tm.Recorder.Record(\"Purr\", time.Since(start), nil)\n",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_MetricsRenamesShadowingParameters(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Shadower::Sleep", "m *MetricsShadower", &w, Options{Mode: Metrics})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := "func (m *MetricsShadower) Sleep(time_ int) (r0 error) {\n" +
+		"\tstart := time.Now()\n" +
+		"\tr0 = m.Inner.Sleep(time_)\n" +
+		"\tm.Recorder.Record(\"Sleep\", time.Since(start), r0)\n"
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+	}
+}
+
+func TestImplWithOptions_MetricsFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "m *MetricsReader[T]", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.Layered", "m *MetricsLayered", &InvalidInterfacePathError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Metrics})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
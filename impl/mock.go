@@ -0,0 +1,130 @@
+package impl
+
+import (
+	"io"
+	"text/template"
+)
+
+// mockPath is the import path of the package mocks are built on.
+const mockPath = "github.com/ajmesa9891/impl/mock"
+
+// mockMethod is a method of a mock, whose parameters and results are all
+// named so that they can be passed on and returned.
+type mockMethod struct {
+	Method
+	Args     string // the arguments passed on to the call's action (e.g., "p, opts...")
+	Results  string // the names of the results (e.g., "n, err")
+	FuncType string // the type of the call's action (e.g., "func([]byte) (int, error)")
+
+	// Do and Ok name the variables of the method, Expect names the
+	// receiver of its EXPECT builder, and Call names the receiver of its
+	// expected call, so that they do not shadow its parameters.
+	Do, Ok, Expect, Call string
+}
+
+var mockTmpl = template.Must(template.New("mock").Parse(
+	"// {{.Type}} is a mock {{.Interface}}. Set the calls it expects with EXPECT,\n" +
+		"// and check that they were all made with Finish.\n" +
+		"type {{.Type}} struct {\n" +
+		"mock {{.Pkg}}.Mock\n" +
+		"}\n\n" +
+		"// EXPECT returns the builder of the calls {{.Recv}} expects.\n" +
+		"func ({{.Recv}} {{.Receiver}}) EXPECT() {{.Type}}Expect {\n" +
+		"return {{.Type}}Expect{&{{.Recv}}.mock}\n" +
+		"}\n\n" +
+		"// Finish reports to t the calls {{.Recv}} expects that were not made.\n" +
+		"func ({{.Recv}} {{.Receiver}}) Finish(t {{.Pkg}}.TestingT) {\n" +
+		"t.Helper()\n" +
+		"{{.Recv}}.mock.Finish(t)\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"if {{.Do}}, {{.Ok}} := {{$.Recv}}.mock.Called(\"{{.Name}}\"{{range .In}}, {{.Name}}{{end}}).({{.FuncType}}); {{.Ok}} {\n" +
+		"{{if .Out}}return {{end}}{{.Do}}({{.Args}})\n" +
+		"}\n" +
+		"{{if .Out}}return\n{{end}}" +
+		"}\n\n" +
+		"{{end}}" +
+		"// {{.Type}}Expect builds the calls a {{.Type}} expects.\n" +
+		"type {{.Type}}Expect struct {\n" +
+		"mock *{{.Pkg}}.Mock\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"// {{.Name}} expects a call to {{.Name}} with arguments matching the given\n" +
+		"// {{$.Pkg}}.Matchers, or equal to the given values.\n" +
+		"func ({{.Expect}} {{$.Type}}Expect) {{.Name}}({{range .In}}{{.Name}} interface{}, {{end}}) {{$.Type}}{{.Name}}Call {\n" +
+		"return {{$.Type}}{{.Name}}Call{ {{.Expect}}.mock.Expect(\"{{.Name}}\"{{range .In}}, {{.Name}}{{end}}) }\n" +
+		"}\n\n" +
+		"// {{$.Type}}{{.Name}}Call is a call to {{.Name}} a {{$.Type}} expects.\n" +
+		"type {{$.Type}}{{.Name}}Call struct {\n" +
+		"*{{$.Pkg}}.Call\n" +
+		"}\n\n" +
+		"{{if .Out}}" +
+		"// Return makes the call return {{.Results}}.\n" +
+		"func ({{.Call}} {{$.Type}}{{.Name}}Call) Return({{range .Out}}{{.Name}} {{.Type}}, {{end}}) {{$.Type}}{{.Name}}Call {\n" +
+		"{{.Call}}.SetAction(func({{range .In}}{{.Type}}, {{end}}) ({{range .Out}}{{.Type}}, {{end}}) { return {{.Results}} })\n" +
+		"return {{.Call}}\n" +
+		"}\n\n" +
+		"// DoAndReturn makes the call return what do returns, called with its arguments.\n" +
+		"func ({{.Call}} {{$.Type}}{{.Name}}Call) DoAndReturn(do {{.FuncType}}) {{$.Type}}{{.Name}}Call {\n" +
+		"{{else}}" +
+		"// Do makes the call call do with its arguments.\n" +
+		"func ({{.Call}} {{$.Type}}{{.Name}}Call) Do(do {{.FuncType}}) {{$.Type}}{{.Name}}Call {\n" +
+		"{{end}}" +
+		"{{.Call}}.SetAction(do)\n" +
+		"return {{.Call}}\n" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderMock writes the declaration of the receiver's type as a mock of the
+// interface i, along with its methods and the types of its EXPECT builder.
+// Mocks are built on the package at mockPath.
+func renderMock(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Mock); err != nil {
+		return err
+	}
+	if !recv.pointer {
+		return NewInvalidReceiverError("mocks hold the calls they expect, so receiver type %q must be a pointer", recv.typeName)
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to mock", i.Name)
+	}
+	if err := checkMembers(i, Mock, "mock", "EXPECT", "Finish"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		Interface string
+		Pkg       string
+		Methods   []mockMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		Interface: i.Name,
+		Pkg:       i.importName(mockPath, "mock"),
+	}
+	for _, m := range i.Methods {
+		reserved := i.reservedNames(m, recvName, data.Pkg)
+		taken := takenNames(reserved, m.In, m.Out)
+		m.In = namedParams(m.In, "p", reserved, taken)
+		m.Out = namedParams(m.Out, "r", reserved, taken)
+		data.Methods = append(data.Methods, mockMethod{
+			Method:   m,
+			Args:     callArgs(m.In),
+			Results:  paramNames(m.Out),
+			FuncType: funcType(m.In, m.Out),
+			Do:       freeName("do", taken),
+			Ok:       freeName("ok", taken),
+			Expect:   freeName("e", takenNames(nil, m.In)),
+			Call:     freeName("c", takenNames([]string{"do"}, m.Out)),
+		})
+	}
+	return renderTemplate(mockTmpl, data, w)
+}
@@ -0,0 +1,84 @@
+package impl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Mock(t *testing.T) {
+	// The mocks the mock package is tested with were written by Mock.
+	mocks, err := ioutil.ReadFile("../mock/mocks_test.go")
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed precondition: %s", err)
+	}
+
+	var w bytes.Buffer
+	result, err := ImplWithOptions("io.ReadWriter", "m *MockReadWriter", &w, Options{Mode: Mock})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	if !strings.Contains(string(mocks), w.String()) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich is not in ../mock/mocks_test.go", w.String())
+	}
+	if want := []Import{{Path: mockPath}}; !reflect.DeepEqual(result.Imports, want) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, want)
+	}
+}
+
+func TestImplWithOptions_MockNamesItsVariables(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.WithUnnamed::Roar", "m *MockRoarer", &w, Options{Mode: Mock})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"func (m *MockRoarer) Roar(p0 context.Context, p1 string, p2 ...int) (r0 error) {\n" +
+			"\tif do, ok := m.mock.Called(\"Roar\", p0, p1, p2).(func(context.Context, string, ...int) error); ok {\n" +
+			"\t\treturn do(p0, p1, p2...)\n",
+		"func (e MockRoarerExpect) Roar(p0 interface{}, p1 interface{}, p2 interface{}) MockRoarerRoarCall {",
+		"func (c MockRoarerRoarCall) Return(r0 error) MockRoarerRoarCall {",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_MockRenamesShadowingParameters(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Shadower::At", "m *MockShadower", &w, Options{Mode: Mock})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"func (m *MockShadower) At(ctx context.Context, time_ time.Time) (r0 int, r1 error) {\n" +
+			"\tif do, ok := m.mock.Called(\"At\", ctx, time_).(func(context.Context, time.Time) (int, error)); ok {\n" +
+			"\t\treturn do(ctx, time_)\n",
+		"func (e MockShadowerExpect) At(ctx interface{}, time_ interface{}) MockShadowerAtCall {",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_MockFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "m MockReader", &InvalidReceiverError{}},
+		{"io.Reader", "m *MockReader[T]", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.Finisher", "m *MockFinisher", &InvalidInterfacePathError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Mock})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
@@ -1,30 +1,165 @@
 package impl
 
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
 type Interface struct {
+	// Name is the name of the interface that declares the methods, as it
+	// is referred to in the package the scaffolding is for (e.g.,
+	// "io.Reader").
+	Name    string
 	Methods []Method
+
+	// Type is how the interface's type is written in the package the
+	// scaffolding is for, including its type arguments (e.g.,
+	// "cache.Store[string, int]"). It names the interface as given, even
+	// when that is an alias of the interface that declares the methods.
+	Type string
+
+	// TypeImports are the packages referred to by the name in Type. Its
+	// type arguments are written as given, so their packages are assumed to
+	// be imported already.
+	TypeImports []Import
+
+	// Imports are the packages referred to by the types of the methods.
+	Imports []Import
+
+	// Warnings describe the constructs of the interface that could not
+	// be handled.
+	Warnings []Warning
+
+	// Mismatches are the methods of the interface the receiver's type has
+	// with a different signature. They are not in Methods.
+	Mismatches []Mismatch
+
+	// q names the packages referred to by the methods.
+	q *qualifier
+}
+
+// importName returns the name the package with the given import path and
+// package name is referred to by alongside the methods, and adds it to the
+// imports of i.
+func (i *Interface) importName(path, pkgName string) string {
+	if i.q == nil {
+		i.q = newQualifier("", nil)
+	}
+	name, imps := i.q.record(func() string { return i.q.qualifyPath(path, pkgName) })
+	i.Imports = mergeImports(i.Imports, imps)
+	return name
+}
+
+// packageName returns the name the package with the given import path and
+// package name is referred to by alongside the methods, like importName, but
+// does not add it to the imports of i. It names the packages a method's body
+// may refer to before it is known whether it does.
+func (i *Interface) packageName(path, pkgName string) string {
+	if i.q == nil {
+		i.q = newQualifier("", nil)
+	}
+	name, _ := i.q.record(func() string { return i.q.qualifyPath(path, pkgName) })
+	return name
+}
+
+// useType returns i.Type, and adds the packages it refers to to the imports
+// of i.
+func (i *Interface) useType() string {
+	i.Imports = mergeImports(i.Imports, i.TypeImports)
+	return i.Type
 }
 
 func NewInterface(m []Method) *Interface {
-	return &Interface{m}
+	return &Interface{Methods: m}
 }
 
 type Method struct {
 	Name string
 	In   []Parameter
 	Out  []Parameter
+
+	// Signature is the type-checked signature of the method. It is nil
+	// for methods that were not built by type-checking their package.
+	Signature *types.Signature
 }
 
 func NewMethod(name string, in []Parameter, out []Parameter) Method {
-	return Method{name, in, out}
+	return Method{Name: name, In: in, Out: out}
 }
 
 type Parameter struct {
 	Name string
 	Type string
+
+	// Var is the type-checked parameter. It is nil for parameters that
+	// were not built by type-checking their package.
+	Var *types.Var
+
+	// Imports are the packages referred to by Type.
+	Imports []Import
+}
+
+// Variadic reports whether p is the variadic parameter of its method, whose
+// type is written with a leading "..." (e.g., "...int").
+func (p Parameter) Variadic() bool {
+	return strings.HasPrefix(p.Type, "...")
 }
 
 // NewParameter creates a new parameter with the given name and type.
 // An empty name creates an unnamed parameter, meant to be returned.
 func NewParameter(name, typeName string) Parameter {
-	return Parameter{name, typeName}
+	return Parameter{Name: name, Type: typeName}
+}
+
+// Import is a package that has to be imported to refer to a type.
+type Import struct {
+	// Name is the name the package is imported as. It is empty unless
+	// the package has to be imported under an alias.
+	Name string
+	Path string
+}
+
+// Warning describes a construct of an interface that could not be handled.
+// The scaffolding written despite it may miss a method or may not compile.
+type Warning struct {
+	// Pos is the position of the construct. It is invalid when it is not
+	// known.
+	Pos token.Position
+
+	// Method is the name of the method the construct belongs to. It is
+	// empty for constructs that do not belong to a single method (e.g., a
+	// type set embedded in the interface).
+	Method string
+
+	Message string
+}
+
+func (w Warning) String() string {
+	s := w.Message
+	if w.Method != "" {
+		s = fmt.Sprintf("method %s: %s", w.Method, s)
+	}
+	if w.Pos.IsValid() {
+		s = w.Pos.String() + ": " + s
+	}
+	return s
+}
+
+// Mismatch is a method of an interface that the receiver's type already has
+// with a different signature, or that is a field of the receiver's type.
+type Mismatch struct {
+	// Method is the interface's method.
+	Method Method
+
+	// Pos is the position of the declaration of the type's method or field.
+	Pos token.Position
+
+	// Want is the type of the interface's method and Have is the type of
+	// the type's method or field, as they would be written in the
+	// scaffolding (e.g., "func(p []byte) (n int, err error)" and
+	// "func(p []byte) int", or "field func()").
+	Want string
+	Have string
 }
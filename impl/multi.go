@@ -0,0 +1,103 @@
+package impl
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// multiMethod is a method of a multi decorator, whose results are at most an
+// error.
+type multiMethod struct {
+	forwardedMethod
+
+	// Errs, Inner and ErrVar name the variables of the method, so that they
+	// do not shadow its parameters.
+	Errs, Inner, ErrVar string
+}
+
+var multiTmpl = template.Must(template.New("multi").Parse(
+	"// {{.Type}} passes the calls made to it on to each of Inners in turn, and\n" +
+		"// returns the errors they return joined. If StopOnError, calls stop at the\n" +
+		"// first error, which is returned alone.\n" +
+		"type {{.Type}} struct {\n" +
+		"Inners      []{{.InnerType}}\n" +
+		"StopOnError bool\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{if .Err}}" +
+		"var {{.Errs}} []error\n" +
+		"for _, {{.Inner}} := range {{$.Recv}}.Inners {\n" +
+		"if {{.ErrVar}} := {{.Inner}}.{{.Name}}({{.Args}}); {{.ErrVar}} != nil {\n" +
+		"if {{$.Recv}}.StopOnError {\n" +
+		"return {{.ErrVar}}\n" +
+		"}\n" +
+		"{{.Errs}} = append({{.Errs}}, {{.ErrVar}})\n" +
+		"}\n" +
+		"}\n" +
+		"return {{$.Errors}}.Join({{.Errs}}...)\n" +
+		"{{else}}" +
+		"for _, {{.Inner}} := range {{$.Recv}}.Inners {\n" +
+		"{{.Inner}}.{{.Name}}({{.Args}})\n" +
+		"}\n" +
+		"{{end}}" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderMulti writes the declaration of the receiver's type as a multi
+// decorator of the interface i, which passes the calls made to it on to each
+// of several inner implementations in turn, along with its methods. The
+// errors they return are joined with errors.Join, unless the calls stop at
+// the first one. Methods that return anything else than an error are not
+// supported, and fail with an *UnsupportedMethodError.
+func renderMulti(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Multi); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to fan out", i.Name)
+	}
+	if err := checkMembers(i, Multi, "Inners", "StopOnError"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		InnerType string
+		Errors    string
+		Methods   []multiMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		InnerType: i.useType(),
+	}
+	var unsupported []string
+	for _, m := range forwardedMethods(i, recvName, i.packageName("errors", "errors")) {
+		if len(m.Out) > 1 || len(m.Out) == 1 && m.Err == "" {
+			unsupported = append(unsupported, m.Name)
+			continue
+		}
+		mm := multiMethod{forwardedMethod: m, Inner: freeName("inner", m.taken)}
+		if m.Err != "" {
+			if data.Errors == "" {
+				data.Errors = i.importName("errors", "errors")
+			}
+			mm.Errs = freeName("errs", m.taken)
+			mm.ErrVar = freeName("err", m.taken)
+		}
+		data.Methods = append(data.Methods, mm)
+	}
+	if len(unsupported) > 0 {
+		return NewUnsupportedMethodError(
+			"interface %s cannot be written in mode %s: methods that return anything else than an error cannot be fanned out: %s",
+			i.Name, Multi, strings.Join(unsupported, ", "))
+	}
+	return renderTemplate(multiTmpl, data, w)
+}
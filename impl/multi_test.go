@@ -0,0 +1,95 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Multi(t *testing.T) {
+	var w bytes.Buffer
+	result, err := ImplWithOptions("impl/impl/test_data/panther.WithUnnamed", "m MultiRoarer", &w, Options{Mode: Multi})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := `// MultiRoarer passes the calls made to it on to each of Inners in turn, and
+// returns the errors they return joined. If StopOnError, calls stop at the
+// first error, which is returned alone.
+type MultiRoarer struct {
+	Inners      []panther.WithUnnamed
+	StopOnError bool
+}
+
+func (m MultiRoarer) Roar(p0 context.Context, p1 string, p2 ...int) (r0 error) {
+	var errs []error
+	for _, inner := range m.Inners {
+		if err := inner.Roar(p0, p1, p2...); err != nil {
+			if m.StopOnError {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m MultiRoarer) Purr(p0 int, loudness float64) {
+	for _, inner := range m.Inners {
+		inner.Purr(p0, loudness)
+	}
+}
+
+`
+	if got := w.String(); got != want {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwant\n%s", got, want)
+	}
+	wantImports := []Import{{Path: "context"}, {Path: "impl/impl/test_data/panther"}, {Path: "errors"}}
+	if !reflect.DeepEqual(result.Imports, wantImports) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, wantImports)
+	}
+}
+
+func TestImplWithOptions_MultiReportsUnsupportedMethods(t *testing.T) {
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Cabinet", "m MultiCabinet", &bytes.Buffer{}, Options{Mode: Multi})
+	if _, ok := err.(*UnsupportedMethodError); !ok {
+		t.Fatalf("ImplWithOptions(...) == %v, want an *UnsupportedMethodError", err)
+	}
+	if want := "Get, Watch, Len"; !strings.Contains(err.Error(), want) {
+		t.Errorf("ImplWithOptions(...) == %v, want it to name %s", err, want)
+	}
+}
+
+func TestImplWithOptions_MultiRenamesShadowingParameters(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Shadower::Notify", "m MultiShadower", &w, Options{Mode: Multi})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"func (m MultiShadower) Notify(errors_ []error) (r0 error) {\n",
+		"\t\tif err := inner.Notify(errors_); err != nil {\n",
+		"\treturn errors.Join(errs...)\n",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_MultiFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Closer", "m *MultiCloser[T]", &InvalidReceiverError{}},
+		{"io.Reader", "m *MultiReader", &UnsupportedMethodError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Multi})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package impl
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// mutexMethod is a method of a mutex decorator.
+type mutexMethod struct {
+	forwardedMethod
+	ReadOnly bool // whether the method is called under the read lock
+}
+
+var mutexTmpl = template.Must(template.New("mutex").Parse(
+	"// {{.Type}} passes the calls made to it on to Inner one at a time, so that\n" +
+		"// it is safe for concurrent use.{{if .ReadOnly}} Calls to its read-only methods,\n" +
+		"// {{.ReadOnly}}, can be passed on at the same time as each other.{{end}}\n" +
+		"type {{.Type}} struct {\n" +
+		"Inner {{.InnerType}}\n" +
+		"mu    {{.Sync}}.{{if .ReadOnly}}RWMutex{{else}}Mutex{{end}}\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{if .ReadOnly}}" +
+		"{{$.Recv}}.mu.RLock()\n" +
+		"defer {{$.Recv}}.mu.RUnlock()\n" +
+		"{{else}}" +
+		"{{$.Recv}}.mu.Lock()\n" +
+		"defer {{$.Recv}}.mu.Unlock()\n" +
+		"{{end}}" +
+		"{{if .Out}}return {{end}}{{$.Recv}}.Inner.{{.Name}}({{.Args}})\n" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderMutex writes the declaration of the receiver's type as a mutex
+// decorator of the interface i, which passes the calls made to it on to an
+// inner implementation one at a time, along with its methods. The calls to
+// the methods named in readOnly are passed on under the read lock of a
+// sync.RWMutex, and the others under its lock, or under the lock of a
+// sync.Mutex if readOnly is empty.
+func renderMutex(i *Interface, recv *receiver, readOnly []string, w io.Writer) error {
+	if err := recv.validateDeclared(Mutex); err != nil {
+		return err
+	}
+	if !recv.pointer {
+		return NewInvalidReceiverError("mutex decorators hold their mutex, so receiver type %q must be a pointer", recv.typeName)
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to guard", i.Name)
+	}
+	if err := checkMembers(i, Mutex, "Inner", "mu"); err != nil {
+		return err
+	}
+	isReadOnly := make(map[string]bool, len(readOnly))
+	for _, name := range readOnly {
+		if _, err := filterMethod(i.Methods, name); err != nil {
+			return err
+		}
+		isReadOnly[name] = true
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		InnerType string
+		Sync      string
+		ReadOnly  string
+		Methods   []mutexMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		InnerType: i.useType(),
+		Sync:      i.importName("sync", "sync"),
+		ReadOnly:  strings.Join(readOnly, ", "),
+	}
+	for _, m := range forwardedMethods(i, recvName) {
+		data.Methods = append(data.Methods, mutexMethod{forwardedMethod: m, ReadOnly: isReadOnly[m.Name]})
+	}
+	return renderTemplate(mutexTmpl, data, w)
+}
@@ -0,0 +1,88 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Mutex(t *testing.T) {
+	var w bytes.Buffer
+	result, err := ImplWithOptions("io.ReadWriter", "s *SyncReadWriter", &w, Options{Mode: Mutex})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := `// SyncReadWriter passes the calls made to it on to Inner one at a time, so that
+// it is safe for concurrent use.
+type SyncReadWriter struct {
+	Inner io.ReadWriter
+	mu    sync.Mutex
+}
+
+func (s *SyncReadWriter) Read(p []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Inner.Read(p)
+}
+
+func (s *SyncReadWriter) Write(p []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Inner.Write(p)
+}
+
+`
+	if got := w.String(); got != want {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwant\n%s", got, want)
+	}
+	if want := []Import{{Path: "io"}, {Path: "sync"}}; !reflect.DeepEqual(result.Imports, want) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, want)
+	}
+}
+
+func TestImplWithOptions_MutexReadOnly(t *testing.T) {
+	var w bytes.Buffer
+	opts := Options{Mode: Mutex, ReadOnly: []string{"Get", "Len"}}
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Cabinet", "s *SyncCabinet", &w, opts)
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"// it is safe for concurrent use. Calls to its read-only methods,\n" +
+			"// Get, Len, can be passed on at the same time as each other.\n",
+		"\tmu    sync.RWMutex\n",
+		"func (s *SyncCabinet) Get(ctx context.Context, key string) (r0 string, r1 error) {\n" +
+			"\ts.mu.RLock()\n" +
+			"\tdefer s.mu.RUnlock()\n",
+		"func (s *SyncCabinet) Put(ctx context.Context, key string, value string) (r0 error) {\n" +
+			"\ts.mu.Lock()\n" +
+			"\tdefer s.mu.Unlock()\n",
+		"func (s *SyncCabinet) Len() (r0 int) {\n" +
+			"\ts.mu.RLock()\n",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_MutexFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		opts          Options
+		want          error
+	}{
+		{"io.Reader", "s SyncReader", Options{Mode: Mutex}, &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.Layered", "s *SyncLayered", Options{Mode: Mutex}, &InvalidInterfacePathError{}},
+		{"io.ReadWriter", "s *SyncReadWriter", Options{Mode: Mutex, ReadOnly: []string{"Peek"}}, &InvalidMethodNameError{}},
+		{"io.ReadWriter", "s *SyncReadWriter", Options{Mode: Wrapper, ReadOnly: []string{"Read"}}, &InvalidModeError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, c.opts)
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ..., %+v) == %v, want a %T", c.interfacePath, c.receiver, c.opts, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package impl
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// checkMembers checks that no method of i has the name of one of members,
+// the fields and methods the type written in the given mode has besides the
+// methods of i.
+func checkMembers(i *Interface, mode Mode, members ...string) error {
+	for _, m := range i.Methods {
+		for _, member := range members {
+			if m.Name == member {
+				return NewInvalidInterfacePathError(
+					"interface %s cannot be written in mode %s: its method %s clashes with a field or method of the type written",
+					i.Name, mode, m.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// takenNames returns the set of names, along with the names of params.
+func takenNames(names []string, params ...[]Parameter) map[string]bool {
+	taken := make(map[string]bool)
+	for _, name := range names {
+		taken[name] = true
+	}
+	for _, ps := range params {
+		for _, p := range ps {
+			taken[p.Name] = true
+		}
+	}
+	return taken
+}
+
+// exportedName returns name with its first letter in upper case (e.g., "Err"
+// for "err").
+func exportedName(name string) string {
+	first, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(first)) + name[size:]
+}
+
+// freeName returns name, followed by as many underscores as needed for it
+// not to be taken, and takes it.
+func freeName(name string, taken map[string]bool) string {
+	for taken[name] {
+		name += "_"
+	}
+	taken[name] = true
+	return name
+}
+
+// namedParams returns a copy of params in which the parameters that are
+// unnamed or named "_" are named after their position, prefixed by prefix
+// (e.g., "p1"), so that they can be referred to, unless prefix is empty.
+// The parameters named as one of reserved (see reservedNames) are renamed
+// (e.g., "r_"). The names are not taken, and are taken by namedParams.
+func namedParams(params []Parameter, prefix string, reserved []string, taken map[string]bool) []Parameter {
+	named := make([]Parameter, len(params))
+	for i, p := range params {
+		switch {
+		case (p.Name == "" || p.Name == "_") && prefix != "":
+			p.Name = freeName(prefix+strconv.Itoa(i), taken)
+		case isReserved(p.Name, reserved):
+			p.Name = freeName(p.Name, taken)
+		}
+		named[i] = p
+	}
+	return named
+}
+
+func isReserved(name string, reserved []string) bool {
+	for _, r := range reserved {
+		if name == r {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedNames returns the names the parameters and results of m must not
+// have in a method written for i, so that they do not hide what its body
+// refers to: recvName, the name of its receiver, the names of the packages
+// the types of m refer to, and pkgNames, the names of the packages its body
+// refers to (see packageName).
+func (i *Interface) reservedNames(m Method, recvName string, pkgNames ...string) []string {
+	reserved := append([]string{recvName}, pkgNames...)
+	for _, imp := range methodImports(m) {
+		name := imp.Name
+		if name == "" && i.q != nil {
+			name = i.q.names[imp.Path]
+		}
+		if name == "" {
+			name = assumedName(imp.Path)
+		}
+		reserved = append(reserved, name)
+	}
+	return reserved
+}
+
+// callArgs returns the arguments that pass on params, which must be named,
+// to a function with the same parameters (e.g., "p, opts...").
+func callArgs(params []Parameter) string {
+	args := make([]string, len(params))
+	for i, p := range params {
+		args[i] = p.Name
+		if p.Variadic() {
+			args[i] += "..."
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// paramNames returns the names of params, which must be named (e.g., "n,
+// err").
+func paramNames(params []Parameter) string {
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// funcType returns the type of a func with the given parameters and results,
+// without their names (e.g., "func([]byte) (int, error)").
+func funcType(in, out []Parameter) string {
+	types := func(params []Parameter) string {
+		s := make([]string, len(params))
+		for i, p := range params {
+			s[i] = p.Type
+		}
+		return strings.Join(s, ", ")
+	}
+	switch len(out) {
+	case 0:
+		return "func(" + types(in) + ")"
+	case 1:
+		return "func(" + types(in) + ") " + types(out)
+	}
+	return "func(" + types(in) + ") (" + types(out) + ")"
+}
+
+// zeroValue returns the zero value of the type of p (e.g., "0", "nil" or
+// "Point{}"), or "*new(T)" for types whose zero value has no literal, such
+// as type parameters, or that were not type-checked.
+func zeroValue(p Parameter) string {
+	t := paramType(p)
+	if t == nil {
+		return "*new(" + p.Type + ")"
+	}
+	if _, ok := t.(*types.TypeParam); ok {
+		return "*new(" + p.Type + ")"
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		case u.Kind() == types.UnsafePointer:
+			return "nil"
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil"
+	case *types.Struct, *types.Array:
+		return p.Type + "{}"
+	}
+	return "*new(" + p.Type + ")"
+}
@@ -0,0 +1,199 @@
+package impl
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// getExprTypeName prints the type expression fieldTypeExpr, found in the
+// source of q's package, qualifying its identifiers with q. It prints every
+// type expression as gofmt would on a single line (e.g., "[4]byte",
+// "<-chan T", "func(a, b int) (bool, error)" or "struct{ A int; B string }").
+func getExprTypeName(fieldTypeExpr ast.Expr, q *qualifier) string {
+	p := &exprPrinter{q: q}
+	p.expr(fieldTypeExpr)
+	return p.String()
+}
+
+// exprPrinter prints expressions found in the source of q's package.
+type exprPrinter struct {
+	strings.Builder
+	q *qualifier
+}
+
+func (p *exprPrinter) expr(e ast.Expr) {
+	switch e := e.(type) {
+	case *ast.Ident:
+		p.WriteString(p.q.localName(e))
+	case *ast.BasicLit:
+		p.WriteString(e.Value)
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			p.WriteString(p.q.importedName(x.Name))
+		} else {
+			p.expr(e.X)
+		}
+		p.WriteString(".")
+		p.WriteString(e.Sel.Name)
+	case *ast.StarExpr:
+		p.WriteString("*")
+		p.expr(e.X)
+	case *ast.ParenExpr:
+		p.WriteString("(")
+		p.expr(e.X)
+		p.WriteString(")")
+	case *ast.UnaryExpr:
+		p.WriteString(e.Op.String())
+		p.expr(e.X)
+	case *ast.BinaryExpr:
+		p.expr(e.X)
+		p.WriteString(" " + e.Op.String() + " ")
+		p.expr(e.Y)
+	case *ast.CallExpr:
+		p.expr(e.Fun)
+		p.WriteString("(")
+		p.exprList(e.Args)
+		if e.Ellipsis.IsValid() {
+			p.WriteString("...")
+		}
+		p.WriteString(")")
+	case *ast.IndexExpr:
+		p.expr(e.X)
+		p.WriteString("[")
+		p.expr(e.Index)
+		p.WriteString("]")
+	case *ast.IndexListExpr:
+		p.expr(e.X)
+		p.WriteString("[")
+		p.exprList(e.Indices)
+		p.WriteString("]")
+	case *ast.Ellipsis:
+		p.WriteString("...")
+		if e.Elt != nil { // nil for array lengths (e.g., "[...]int")
+			p.expr(e.Elt)
+		}
+	case *ast.ArrayType:
+		p.WriteString("[")
+		if e.Len != nil {
+			p.expr(e.Len)
+		}
+		p.WriteString("]")
+		p.expr(e.Elt)
+	case *ast.MapType:
+		p.WriteString("map[")
+		p.expr(e.Key)
+		p.WriteString("]")
+		p.expr(e.Value)
+	case *ast.ChanType:
+		switch e.Dir {
+		case ast.SEND:
+			p.WriteString("chan<- ")
+		case ast.RECV:
+			p.WriteString("<-chan ")
+		default:
+			p.WriteString("chan ")
+		}
+		p.expr(e.Value)
+	case *ast.FuncType:
+		p.WriteString("func")
+		p.signature(e)
+	case *ast.StructType:
+		p.WriteString("struct{")
+		p.fields(e.Fields, "; ", func(f *ast.Field) {
+			p.names(f.Names)
+			if len(f.Names) > 0 {
+				p.WriteString(" ")
+			}
+			p.expr(f.Type)
+			if f.Tag != nil {
+				p.WriteString(" " + f.Tag.Value)
+			}
+		})
+		p.WriteString("}")
+	case *ast.InterfaceType:
+		p.WriteString("interface{")
+		p.fields(e.Methods, "; ", func(f *ast.Field) {
+			if ft, isMethod := f.Type.(*ast.FuncType); isMethod && len(f.Names) > 0 {
+				p.WriteString(f.Names[0].Name)
+				p.signature(ft)
+				return
+			}
+			p.expr(f.Type)
+		})
+		p.WriteString("}")
+	default:
+		// Only expressions that cannot be types (e.g., composite literals)
+		// are left, so they are printed as they are.
+		p.q.warn(e.Pos(), "expression %s is not a type and was written without qualifying it", types.ExprString(e))
+		p.WriteString(types.ExprString(e))
+	}
+}
+
+func (p *exprPrinter) exprList(list []ast.Expr) {
+	for i, e := range list {
+		if i > 0 {
+			p.WriteString(", ")
+		}
+		p.expr(e)
+	}
+}
+
+func (p *exprPrinter) names(names []*ast.Ident) {
+	for i, name := range names {
+		if i > 0 {
+			p.WriteString(", ")
+		}
+		p.WriteString(name.Name)
+	}
+}
+
+// fields prints the fields of fl separated by sep using field. Non-empty
+// lists are padded with spaces (e.g., "{ A int }") as gofmt does.
+func (p *exprPrinter) fields(fl *ast.FieldList, sep string, field func(*ast.Field)) {
+	if fl == nil || len(fl.List) == 0 {
+		return
+	}
+	p.WriteString(" ")
+	for i, f := range fl.List {
+		if i > 0 {
+			p.WriteString(sep)
+		}
+		field(f)
+	}
+	p.WriteString(" ")
+}
+
+// signature prints the parameters and results of ft.
+func (p *exprPrinter) signature(ft *ast.FuncType) {
+	p.WriteString("(")
+	p.params(ft.Params)
+	p.WriteString(")")
+	if ft.Results == nil || len(ft.Results.List) == 0 {
+		return
+	}
+	p.WriteString(" ")
+	if len(ft.Results.List) == 1 && len(ft.Results.List[0].Names) == 0 {
+		p.expr(ft.Results.List[0].Type)
+		return
+	}
+	p.WriteString("(")
+	p.params(ft.Results)
+	p.WriteString(")")
+}
+
+func (p *exprPrinter) params(fl *ast.FieldList) {
+	if fl == nil {
+		return
+	}
+	for i, f := range fl.List {
+		if i > 0 {
+			p.WriteString(", ")
+		}
+		p.names(f.Names)
+		if len(f.Names) > 0 {
+			p.WriteString(" ")
+		}
+		p.expr(f.Type)
+	}
+}
@@ -0,0 +1,138 @@
+package impl
+
+import (
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestGetExprTypeName_RoundTrips(t *testing.T) {
+	cases := []string{
+		"int",
+		"io.Reader",
+		"*io.Reader",
+		"[]byte",
+		"[4]byte",
+		"[N + 1]byte",
+		"[...]int",
+		"[len(x)]int",
+		"*[]byte",
+		"*[4]*models.Account",
+		"**T",
+		"map[string][]*models.Account",
+		"map[[2]int]func() error",
+		"chan int",
+		"<-chan int",
+		"chan<- int",
+		"chan<- <-chan int",
+		"chan (<-chan int)",
+		"(int)",
+		"*(models.Account)",
+		"func()",
+		"func(int) bool",
+		"func(a, b int, c ...string) (x int, err error)",
+		"func(int, string) (bool, error)",
+		"func(func(int) int) func() int",
+		"struct{}",
+		"struct{ A int }",
+		"struct{ A, B int; C string `json:\"c\"`; io.Reader; *models.Account }",
+		"interface{}",
+		"interface{ Get() models.Account }",
+		"interface{ io.Reader; Close() error; Peek(n int) ([]byte, error) }",
+		"interface{ ~int | ~string }",
+		"Store[string, *models.Account]",
+		"List[T]",
+		"pkg.Store[K, V]",
+	}
+	for _, want := range cases {
+		expr, err := parser.ParseExpr(want)
+		if err != nil {
+			t.Errorf("getExprTypeName(%q) failed precondition: %s", want, err)
+			continue
+		}
+		if got := getExprTypeName(expr, nil); got != want {
+			t.Errorf("getExprTypeName(%q) == %q", want, got)
+		}
+	}
+}
+
+func TestGetExprTypeName_Qualifies(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", `package p; import m "example.com/models"`, 0)
+	if err != nil {
+		t.Fatalf("getExprTypeName(...) failed precondition: %s", err)
+	}
+	q := newQualifier("example.com/app", nil).from(types.NewPackage("example.com/p", "p"), nil, file)
+
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"int", "int"},
+		{"Account", "p.Account"},
+		{"m.Account", "models.Account"},
+		{"io.Reader", "io.Reader"},
+		{"[N]m.Account", "[p.N]models.Account"},
+		{"func(Account) (m.Account, error)", "func(p.Account) (models.Account, error)"},
+		{"struct{ Name string; Account }", "struct{ Name string; p.Account }"},
+		{"interface{ Get() Account }", "interface{ Get() p.Account }"},
+		{"Store[Key, *m.Account]", "p.Store[p.Key, *models.Account]"},
+	}
+	for _, c := range cases {
+		expr, err := parser.ParseExpr(c.in)
+		if err != nil {
+			t.Errorf("getExprTypeName(%q) failed precondition: %s", c.in, err)
+			continue
+		}
+		if got := getExprTypeName(expr, q); got != c.want {
+			t.Errorf("getExprTypeName(%q) == %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestGetExprTypeName_ReplacesTypeParameters(t *testing.T) {
+	q := newQualifier("example.com/app", nil).from(types.NewPackage("example.com/p", "p"), nil, nil).forMethod("Get")
+	q.typeArgs = map[string]types.Type{"K": types.Typ[types.String], "V": nil}
+
+	expr, err := parser.ParseExpr("func(K, Account) Box[V]")
+	if err != nil {
+		t.Fatalf("getExprTypeName(...) failed precondition: %s", err)
+	}
+	if got, want := getExprTypeName(expr, q), "func(string, p.Account) p.Box[V]"; got != want {
+		t.Errorf("getExprTypeName(...) == %q, want %q", got, want)
+	}
+	if got := len(*q.warnings); got != 1 {
+		t.Errorf("getExprTypeName(...) warned %d times, want once for V: %v", got, *q.warnings)
+	}
+}
+
+func TestGetExprTypeName_Warns(t *testing.T) {
+	cases := []struct {
+		in           string
+		wantWarnings int
+	}{
+		{"[len(x)]int", 0},
+		{"func(struct{ A int }) error", 0},
+		{"[T{}.N]int", 1},
+		{"[(T{}).N][len([]int{})]int", 2},
+	}
+	for _, c := range cases {
+		expr, err := parser.ParseExpr(c.in)
+		if err != nil {
+			t.Errorf("getExprTypeName(%q) failed precondition: %s", c.in, err)
+			continue
+		}
+		q := newQualifier("example.com/app", nil).forMethod("Get")
+		if got := getExprTypeName(expr, q); got != c.in {
+			t.Errorf("getExprTypeName(%q) == %q", c.in, got)
+		}
+		if got := len(*q.warnings); got != c.wantWarnings {
+			t.Errorf("getExprTypeName(%q) warned %d times, want %d: %v", c.in, got, c.wantWarnings, *q.warnings)
+		}
+		for _, w := range *q.warnings {
+			if w.Method != "Get" {
+				t.Errorf("getExprTypeName(%q) warned for method %q, want %q", c.in, w.Method, "Get")
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package impl
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	pathpkg "path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// qualifier names the packages referred to by the types of an interface's
+// methods, as seen from the package the scaffolding is written to. Types
+// declared in that package are left unqualified. Every other package is
+// referred to by its name, or by an alias when that name is already taken
+// by a different package.
+type qualifier struct {
+	pkgPath string            // import path of the package the scaffolding is for
+	names   map[string]string // package path -> name it is referred to by
+	paths   map[string]string // name -> package path it refers to
+
+	// used are the imports of the packages qualified since the last call
+	// to record began.
+	used []Import
+
+	// src is the package whose source is printed when a type could not
+	// be type-checked. Its unqualified identifiers are declared in it, and
+	// its qualified identifiers refer to the imports of file. fset holds
+	// the positions of its source.
+	src  *types.Package
+	fset *token.FileSet
+	file *ast.File
+
+	// typeArgs are the type parameters of the declaration whose source is
+	// printed, by name, with the type arguments they stand for, or with nil
+	// if those are not known.
+	typeArgs map[string]types.Type
+
+	// method is the name of the method whose types are printed, if any.
+	method string
+
+	// warnings are the constructs that could not be handled. They are
+	// shared by every qualifier derived from the same one.
+	warnings *[]Warning
+}
+
+// newQualifier returns a qualifier for scaffolding written to the package
+// with import path pkgPath, in a file that already has the given imports.
+func newQualifier(pkgPath string, imports []Import) *qualifier {
+	q := &qualifier{
+		pkgPath:  pkgPath,
+		names:    make(map[string]string),
+		paths:    make(map[string]string),
+		warnings: &[]Warning{},
+	}
+	for _, imp := range imports {
+		name := imp.Name
+		if name == "" {
+			name = assumedName(imp.Path)
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		q.names[imp.Path] = name
+		q.paths[name] = imp.Path
+	}
+	return q
+}
+
+// qualify returns the name p is referred to by, or "" if p is the package
+// the scaffolding is written to. It implements types.Qualifier.
+func (q *qualifier) qualify(p *types.Package) string {
+	return q.qualifyPath(p.Path(), p.Name())
+}
+
+// qualifyPath returns the name the package with the given import path and
+// package name is referred to by, or "" if it is the package the
+// scaffolding is written to.
+func (q *qualifier) qualifyPath(path, pkgName string) string {
+	if path == q.pkgPath {
+		return ""
+	}
+	name, ok := q.names[path]
+	if !ok {
+		name = pkgName
+		for i := 2; q.paths[name] != ""; i++ {
+			name = fmt.Sprintf("%s%d", pkgName, i)
+		}
+		q.names[path] = name
+		q.paths[name] = path
+	}
+
+	imp := Import{Path: path}
+	if name != pkgName {
+		imp.Name = name
+	}
+	q.used = mergeImports(q.used, []Import{imp})
+	return name
+}
+
+// mergeImports appends to imps the imports in more that it does not have.
+func mergeImports(imps []Import, more []Import) []Import {
+	for _, imp := range more {
+		if !hasImport(imps, imp) {
+			imps = append(imps, imp)
+		}
+	}
+	return imps
+}
+
+func hasImport(imps []Import, imp Import) bool {
+	for _, i := range imps {
+		if i == imp {
+			return true
+		}
+	}
+	return false
+}
+
+// record calls print and returns what it printed along with the imports of
+// the packages it qualified. Calls to record can be nested.
+func (q *qualifier) record(print func() string) (string, []Import) {
+	prev := q.used
+	q.used = []Import{}
+	s := print()
+	used := q.used
+	q.used = mergeImports(prev, used)
+	return s, used
+}
+
+// typeString prints t as it would be written in the package the scaffolding
+// is written to.
+func (q *qualifier) typeString(t types.Type) string {
+	return types.TypeString(t, q.qualify)
+}
+
+// from returns a qualifier that shares q's package names and prints types
+// from the source of file, which belongs to package src and whose
+// positions are held by fset. Its identifiers are not type parameters until
+// typeArgs is set.
+func (q *qualifier) from(src *types.Package, fset *token.FileSet, file *ast.File) *qualifier {
+	c := *q
+	c.src = src
+	c.fset = fset
+	c.file = file
+	c.typeArgs = nil
+	return &c
+}
+
+// forMethod returns a qualifier that shares q's package names and
+// warnings, and attributes warnings to the method with the given name.
+func (q *qualifier) forMethod(name string) *qualifier {
+	c := *q
+	c.method = name
+	return &c
+}
+
+// warn records a warning about the construct found at pos, which could not
+// be handled.
+func (q *qualifier) warn(pos token.Pos, format string, args ...interface{}) {
+	w := Warning{Method: q.methodName(), Message: fmt.Sprintf(format, args...)}
+	if q != nil && q.fset != nil && pos.IsValid() {
+		w.Pos = q.fset.Position(pos)
+	}
+	dl("    warning: %s\n", w)
+	if q != nil && q.warnings != nil {
+		*q.warnings = append(*q.warnings, w)
+	}
+}
+
+func (q *qualifier) methodName() string {
+	if q == nil {
+		return ""
+	}
+	return q.method
+}
+
+// localName returns how ident, an unqualified identifier found in the
+// source of q's package, is written in the package the scaffolding is for.
+// Predeclared identifiers (e.g., int or error) are left alone, and type
+// parameters are written as the type arguments they stand for.
+func (q *qualifier) localName(ident *ast.Ident) string {
+	name := ident.Name
+	if q == nil || q.src == nil || types.Universe.Lookup(name) != nil {
+		return name
+	}
+	if arg, ok := q.typeArgs[name]; ok {
+		if arg == nil {
+			q.warn(ident.Pos(), "type parameter %s could not be replaced by its type argument", name)
+			return name
+		}
+		return q.typeString(arg)
+	}
+	if qual := q.qualify(q.src); qual != "" {
+		return qual + "." + name
+	}
+	return name
+}
+
+// importedName returns how the package imported as name by q's file is
+// referred to in the package the scaffolding is for. The package is
+// resolved through the file's import declarations, so its alias in the
+// file (if any) does not leak into the scaffolding. Names that are not
+// imported by the file are left alone.
+func (q *qualifier) importedName(name string) string {
+	if q == nil {
+		return name
+	}
+	if path, ok := fileImportPath(q.file, name); ok {
+		return q.qualifyPath(path, assumedName(path))
+	}
+	return name
+}
+
+// assumedName returns the name a package is assumed to have from its import
+// path: its last element, skipping major version suffixes (e.g., "v2"),
+// without a "go-" prefix and up to the first character that cannot be in an
+// identifier. It is used for packages that could not be loaded.
+func assumedName(path string) string {
+	base := pathpkg.Base(path)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil && pathpkg.Dir(path) != "." {
+			base = pathpkg.Base(pathpkg.Dir(path))
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
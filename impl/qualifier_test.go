@@ -0,0 +1,23 @@
+package impl
+
+import "testing"
+
+func TestAssumedName(t *testing.T) {
+	cases := []struct {
+		path     string
+		wantName string
+	}{
+		{"io", "io"},
+		{"net/http", "http"},
+		{"ultimatesoftware.com/accountstore/models", "models"},
+		{"github.com/user/project/v2", "project"},
+		{"github.com/user/go-yaml", "yaml"},
+		{"gopkg.in/yaml.v2", "yaml"},
+		{"v2", "v2"},
+	}
+	for _, c := range cases {
+		if got := assumedName(c.path); got != c.wantName {
+			t.Errorf("assumedName(%q) == %q, want %q", c.path, got, c.wantName)
+		}
+	}
+}
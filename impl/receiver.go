@@ -0,0 +1,242 @@
+package impl
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// receiver is the receiver of the scaffolded methods (e.g., "l *List[T]").
+type receiver struct {
+	name       string   // empty for unnamed receivers (e.g., "*List[T]")
+	typeName   string   // the receiver's base type (e.g., "List")
+	pointer    bool     // whether the receiver is a pointer to its base type
+	typeParams []string // the names of the receiver type's type parameters
+}
+
+// parseReceiver parses s as a method receiver. An empty s is a receiver
+// with no name nor type.
+func parseReceiver(s string) (*receiver, error) {
+	if strings.TrimSpace(s) == "" {
+		return &receiver{}, nil
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p; func ("+s+") m() {}", 0)
+	if err != nil {
+		return nil, NewInvalidReceiverError("invalid receiver %q: %s", s, err)
+	}
+	recv := file.Decls[0].(*ast.FuncDecl).Recv
+	if recv == nil || len(recv.List) != 1 || len(recv.List[0].Names) > 1 {
+		return nil, NewInvalidReceiverError("invalid receiver %q: must be a single receiver (e.g., \"r *Repo\")", s)
+	}
+
+	r := &receiver{}
+	field := recv.List[0]
+	if len(field.Names) == 1 {
+		r.name = field.Names[0].Name
+	}
+	expr := field.Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, r.pointer = star.X, true
+	}
+	var typeParams []ast.Expr
+	switch index := expr.(type) {
+	case *ast.IndexExpr:
+		expr, typeParams = index.X, []ast.Expr{index.Index}
+	case *ast.IndexListExpr:
+		expr, typeParams = index.X, index.Indices
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		dl("receiver type %q is not a type name: it cannot be validated", types.ExprString(expr))
+		return r, nil
+	}
+	r.typeName = ident.Name
+	for _, typeParam := range typeParams {
+		param, ok := typeParam.(*ast.Ident)
+		if !ok {
+			return nil, NewInvalidReceiverError(
+				"invalid receiver %q: type parameters must be names, but got %q", s, types.ExprString(typeParam))
+		}
+		r.typeParams = append(r.typeParams, param.Name)
+	}
+	return r, nil
+}
+
+// typeString returns how the receiver's type is written, without its type
+// parameters (e.g., "*List").
+func (r *receiver) typeString() string {
+	if r.pointer {
+		return "*" + r.typeName
+	}
+	return r.typeName
+}
+
+// varName returns the receiver's name, or the lowercased first letter of
+// its type's name when it is unnamed (e.g., "l" for "*List").
+func (r *receiver) varName() string {
+	if r.name != "" && r.name != "_" {
+		return r.name
+	}
+	first, _ := utf8.DecodeRuneInString(r.typeName)
+	return string(unicode.ToLower(first))
+}
+
+// validateDeclared checks that the receiver's type can be declared by the
+// scaffolding written in the given mode.
+func (r *receiver) validateDeclared(mode Mode) error {
+	if r.typeName == "" {
+		return NewInvalidReceiverError("mode %s needs a receiver with a type name (e.g., \"r *Receiver\")", mode)
+	}
+	if len(r.typeParams) > 0 {
+		return NewInvalidReceiverError("mode %s cannot declare generic types, but receiver type %q has type parameters", mode, r.typeName)
+	}
+	return nil
+}
+
+// validate checks that the receiver has as many type parameters as its type
+// is declared with in the package with import path pkgPath, resolved from
+// dir. Receivers whose type is not declared yet (or whose package is not
+// known) are assumed valid.
+func (r *receiver) validate(pkgPath, dir string) error {
+	_, typeName := r.lookup(pkgPath, dir)
+	if typeName == nil {
+		dl("receiver type %q is not declared in %q: it cannot be validated", r.typeName, pkgPath)
+		return nil
+	}
+	named, ok := typeName.Type().(*types.Named)
+	if !ok {
+		return NewInvalidReceiverError("receiver type %q must be a type declared in %q", r.typeName, pkgPath)
+	}
+	if got, want := len(r.typeParams), named.TypeParams().Len(); got != want {
+		return NewInvalidReceiverError("receiver type %q has %d type parameters, but is declared with %d: %s",
+			r.typeName, got, want, named.Obj().Type())
+	}
+	return nil
+}
+
+// lookup returns the declaration of the receiver's type in the package with
+// import path pkgPath, resolved from dir, along with the package. It returns
+// a nil declaration if the type is not declared (yet), if it is an alias, or
+// if the package is not known.
+func (r *receiver) lookup(pkgPath, dir string) (*typedPackage, *types.TypeName) {
+	if r.typeName == "" || pkgPath == "" {
+		return nil, nil
+	}
+	pkg, err := loaderFor(dir).load(pkgPath, "")
+	if err != nil || pkg.types == nil {
+		return nil, nil
+	}
+	typeName, ok := pkg.types.Scope().Lookup(r.typeName).(*types.TypeName)
+	if !ok || typeName.IsAlias() {
+		return pkg, nil
+	}
+	return pkg, typeName
+}
+
+// missing splits methods into the methods the receiver's type, declared in
+// the package with import path pkgPath resolved from dir, does not have yet
+// and the methods it has with a different signature. Methods are had when
+// the type or a pointer to it has them, whether declared or promoted from
+// embedded fields. All methods are missing when the type is not declared.
+// Signatures are written as qualified by q.
+func (r *receiver) missing(methods []Method, pkgPath, dir string, q *qualifier) ([]Method, []Mismatch) {
+	pkg, typeName := r.lookup(pkgPath, dir)
+	if typeName == nil {
+		dl("receiver type %q is not declared in %q: all methods are missing", r.typeName, pkgPath)
+		return methods, nil
+	}
+	var missing []Method
+	var mismatches []Mismatch
+	for _, m := range methods {
+		obj, _, _ := types.LookupFieldOrMethod(typeName.Type(), true, pkg.types, m.Name)
+		if obj == nil {
+			missing = append(missing, m)
+			continue
+		}
+		fn, isMethod := obj.(*types.Func)
+		if isMethod && (m.Signature == nil || sameSignature(m.Signature, fn.Type().(*types.Signature))) {
+			dl("    receiver type %q already has method %q", r.typeName, m.Name)
+			continue
+		}
+		mismatch := Mismatch{
+			Method: m,
+			Pos:    pkg.fset.Position(obj.Pos()),
+			Want:   methodType(m),
+			Have:   q.typeString(obj.Type()),
+		}
+		if !isMethod {
+			mismatch.Have = "field " + mismatch.Have
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	return missing, mismatches
+}
+
+// methodType returns the type of m (e.g., "func(p []byte) (n int, err error)").
+func methodType(m Method) string {
+	params := func(ps []Parameter) string {
+		list := make([]string, len(ps))
+		for i, p := range ps {
+			list[i] = strings.TrimSpace(p.Name + " " + p.Type)
+		}
+		return strings.Join(list, ", ")
+	}
+	s := "func(" + params(m.In) + ")"
+	if len(m.Out) == 1 && m.Out[0].Name == "" {
+		return s + " " + m.Out[0].Type
+	} else if len(m.Out) > 0 {
+		return s + " (" + params(m.Out) + ")"
+	}
+	return s
+}
+
+// sameSignature reports whether the parameters and results of want and have
+// are of the same types. Types that could not be type-checked in want are
+// assumed to be the same. Type parameters are the same as the type
+// parameters with the same name, since the type parameters of a receiver
+// and of the interface instantiated with them are declared apart.
+func sameSignature(want, have *types.Signature) bool {
+	if !isValidType(want) {
+		return true
+	}
+	return want.Variadic() == have.Variadic() &&
+		sameTypes(want.Params(), have.Params()) && sameTypes(want.Results(), have.Results())
+}
+
+func sameTypes(want, have *types.Tuple) bool {
+	if want.Len() != have.Len() {
+		return false
+	}
+	qualify := func(p *types.Package) string { return p.Path() }
+	for i := 0; i < want.Len(); i++ {
+		if types.TypeString(want.At(i).Type(), qualify) != types.TypeString(have.At(i).Type(), qualify) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeArgs returns the receiver's type parameters as type arguments for
+// a generic interface with the given number of type parameters, so that the
+// methods scaffolded refer to the receiver's type parameters. It returns nil
+// if the receiver has no type parameters.
+func (r *receiver) typeArgs(interfaceName string, n int) ([]ast.Expr, error) {
+	if len(r.typeParams) == 0 {
+		return nil, nil
+	}
+	if len(r.typeParams) != n {
+		return nil, NewInvalidTypeArgumentsError(
+			"generic interface %q has %d type parameters and receiver type %q has %d: "+
+				"specify the interface's type arguments in its path",
+			interfaceName, n, r.typeName, len(r.typeParams))
+	}
+	args := make([]ast.Expr, len(r.typeParams))
+	for i, param := range r.typeParams {
+		args[i] = ast.NewIdent(param)
+	}
+	return args, nil
+}
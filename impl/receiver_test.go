@@ -0,0 +1,35 @@
+package impl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReceiver(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    *receiver
+		wantErr error
+	}{
+		{"r *Repo", &receiver{name: "r", typeName: "Repo", pointer: true}, nil},
+		{"r Repo", &receiver{name: "r", typeName: "Repo"}, nil},
+		{"*Repo", &receiver{typeName: "Repo", pointer: true}, nil},
+		{"Repo", &receiver{typeName: "Repo"}, nil},
+		{"l *List[T]", &receiver{name: "l", typeName: "List", pointer: true, typeParams: []string{"T"}}, nil},
+		{"c Cache[K, V]", &receiver{name: "c", typeName: "Cache", typeParams: []string{"K", "V"}}, nil},
+		{"", &receiver{}, nil},
+		{"f *os.File", &receiver{name: "f", pointer: true}, nil},
+
+		{"l *List[", nil, &InvalidReceiverError{}},
+		{"a, b Repo", nil, &InvalidReceiverError{}},
+		{"l *List[*T]", nil, &InvalidReceiverError{}},
+	}
+	for _, c := range cases {
+		got, err := parseReceiver(c.in)
+		if reflect.TypeOf(c.wantErr) != reflect.TypeOf(err) {
+			t.Errorf("parseReceiver(%q): wanted error type \"%T\", got \"%T\"", c.in, c.wantErr, err)
+		} else if c.wantErr == nil && !reflect.DeepEqual(got, c.want) {
+			t.Errorf("parseReceiver(%q) == %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
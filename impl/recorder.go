@@ -0,0 +1,225 @@
+package impl
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// replayPath is the import path of the package recorders are built on.
+const replayPath = "github.com/ajmesa9891/impl/replay"
+
+// recorderMethod is a method of a recorder, whose parameters and results are
+// all named so that they can be passed on, recorded and replayed.
+type recorderMethod struct {
+	Method
+	Args     string // the arguments passed on to the inner implementation (e.g., "p, opts...")
+	Results  string // the names of the results (e.g., "n, err")
+	Recorded string // pointers to the arguments recorded (e.g., "&p, &opts")
+	Returned string // pointers to the results recorded (e.g., "&n, &err")
+	Call     string // the name of the method's variable holding the call
+}
+
+var recorderTmpl = template.Must(template.New("recorder").Parse(
+	"// {{.Type}} records the calls made to it to Tape, passing them on to Inner,\n" +
+		"// or answers them from Tape, depending on whether Tape records.\n" +
+		"type {{.Type}} struct {\n" +
+		"Inner {{.InnerType}}\n" +
+		"Tape  *{{.Pkg}}.Tape\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{.Call}} := {{$.Recv}}.Tape.Call(\"{{.Name}}\"{{if .Recorded}}, {{.Recorded}}{{end}})\n" +
+		"if {{.Call}}.Recording() {\n" +
+		"{{if .Out}}{{.Results}} = {{end}}{{$.Recv}}.Inner.{{.Name}}({{.Args}})\n" +
+		"}\n" +
+		"{{if .Out}}{{.Call}}.Return({{.Returned}})\nreturn\n{{end}}" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderRecorder writes the declaration of the receiver's type as a recorder
+// of the interface i, which records the calls made to it to a golden file
+// or replays them from it, along with its methods. Recorders are built on
+// the package at replayPath. Parameters that cannot be recorded as JSON are
+// left out of the recorded calls, with a warning, while results that cannot
+// be replayed from JSON fail with an *UnserializableTypeError. Parameters of
+// type context.Context are left out silently.
+func renderRecorder(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Recorder); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to record", i.Name)
+	}
+	if err := checkMembers(i, Recorder, "Inner", "Tape"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		Interface string
+		InnerType string
+		Pkg       string
+		Methods   []recorderMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		Interface: i.Name,
+		InnerType: i.useType(),
+		Pkg:       i.importName(replayPath, "replay"),
+	}
+	for _, m := range i.Methods {
+		reserved := i.reservedNames(m, recvName)
+		taken := takenNames(reserved, m.In, m.Out)
+		m.In = namedParams(m.In, "p", reserved, taken)
+		m.Out = namedParams(m.Out, "r", reserved, taken)
+		var recorded, returned []string
+		for _, p := range m.In {
+			if p.Var != nil && isContext(p.Var.Type()) {
+				continue
+			}
+			if problem := jsonProblem(paramType(p)); problem != "" {
+				i.Warnings = append(i.Warnings, Warning{
+					Method:  m.Name,
+					Message: fmt.Sprintf("parameter %s is not recorded nor compared when replayed: %s", p.Name, problem),
+				})
+				continue
+			}
+			recorded = append(recorded, "&"+p.Name)
+		}
+		for _, p := range m.Out {
+			if problem := jsonProblem(paramType(p)); problem != "" {
+				return NewUnserializableTypeError("result %s of method %s cannot be replayed: %s", p.Name, m.Name, problem)
+			}
+			returned = append(returned, "&"+p.Name)
+		}
+		data.Methods = append(data.Methods, recorderMethod{
+			Method:   m,
+			Args:     callArgs(m.In),
+			Results:  paramNames(m.Out),
+			Recorded: strings.Join(recorded, ", "),
+			Returned: strings.Join(returned, ", "),
+			Call:     freeName("call", taken),
+		})
+	}
+	return renderTemplate(recorderTmpl, data, w)
+}
+
+// paramType returns the type-checked type of p (a slice for variadic
+// parameters), or nil if it is not known.
+func paramType(p Parameter) types.Type {
+	if p.Var == nil || !isValidType(p.Var.Type()) {
+		return nil
+	}
+	return p.Var.Type()
+}
+
+// isContext reports whether t is context.Context.
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+// jsonProblem describes why values of type t cannot be encoded as JSON or
+// decoded back by encoding/json (e.g., "func() is a func"), or returns "" if
+// they can, as far as their type tells. Errors can be, as their message. A
+// nil t is assumed to be fine.
+func jsonProblem(t types.Type) string {
+	return jsonProblemSeen(t, map[types.Type]bool{})
+}
+
+func jsonProblemSeen(t types.Type, seen map[types.Type]bool) string {
+	if t == nil || seen[t] {
+		return ""
+	}
+	seen[t] = true
+	if types.Identical(t, types.Universe.Lookup("error").Type()) || isJSONMarshaler(t) {
+		return ""
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsComplex != 0:
+			return fmt.Sprintf("%s is a complex number", t)
+		case u.Kind() == types.UnsafePointer:
+			return fmt.Sprintf("%s is an unsafe.Pointer", t)
+		}
+	case *types.Chan:
+		return fmt.Sprintf("%s is a channel", t)
+	case *types.Signature:
+		return fmt.Sprintf("%s is a func", t)
+	case *types.Interface:
+		if !u.Empty() {
+			return fmt.Sprintf("%s is an interface, whose dynamic type is not recorded", t)
+		}
+	case *types.Pointer:
+		return jsonProblemSeen(u.Elem(), seen)
+	case *types.Slice:
+		return jsonProblemSeen(u.Elem(), seen)
+	case *types.Array:
+		return jsonProblemSeen(u.Elem(), seen)
+	case *types.Map:
+		if key, ok := u.Key().Underlying().(*types.Basic); !(ok && key.Info()&(types.IsString|types.IsInteger) != 0) && !isTextMarshaler(u.Key()) {
+			return fmt.Sprintf("%s has keys that are neither strings, integers nor encoding.TextMarshalers", t)
+		}
+		return jsonProblemSeen(u.Elem(), seen)
+	case *types.Struct:
+		exported := 0
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			if !f.Exported() && !f.Embedded() || strings.HasPrefix(u.Tag(i), `json:"-"`) {
+				continue
+			}
+			exported++
+			if problem := jsonProblemSeen(f.Type(), seen); problem != "" {
+				return fmt.Sprintf("field %s of %s: %s", f.Name(), t, problem)
+			}
+		}
+		if exported == 0 && u.NumFields() > 0 {
+			return fmt.Sprintf("%s has no exported fields", t)
+		}
+	}
+	return ""
+}
+
+// isJSONMarshaler reports whether a pointer to t has the methods of both
+// json.Marshaler and json.Unmarshaler, or of their encoding.Text
+// counterparts.
+func isJSONMarshaler(t types.Type) bool {
+	return hasMethods(t, "MarshalJSON", "UnmarshalJSON") || isTextMarshaler(t)
+}
+
+// isTextMarshaler reports whether a pointer to t has the methods of both
+// encoding.TextMarshaler and encoding.TextUnmarshaler.
+func isTextMarshaler(t types.Type) bool {
+	return hasMethods(t, "MarshalText", "UnmarshalText")
+}
+
+func hasMethods(t types.Type, names ...string) bool {
+	if _, ok := t.(*types.Named); !ok {
+		return false
+	}
+	mset := types.NewMethodSet(types.NewPointer(t))
+	for _, name := range names {
+		found := false
+		for i := 0; i < mset.Len(); i++ {
+			if mset.At(i).Obj().Name() == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package impl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Recorder(t *testing.T) {
+	// The recorders the replay package is tested with were written by Recorder.
+	recorders, err := ioutil.ReadFile("../replay/recorders_test.go")
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed precondition: %s", err)
+	}
+
+	var w bytes.Buffer
+	result, err := ImplWithOptions("io.ReadWriter", "r *RecordReadWriter", &w, Options{Mode: Recorder})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	if !strings.Contains(string(recorders), w.String()) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich is not in ../replay/recorders_test.go", w.String())
+	}
+	if want := []Import{{Path: "io"}, {Path: replayPath}}; !reflect.DeepEqual(result.Imports, want) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, want)
+	}
+}
+
+func TestImplWithOptions_RecorderSkipsParameters(t *testing.T) {
+	var w bytes.Buffer
+	result, err := ImplWithOptions("impl/impl/test_data/panther.Walker", "r *RecordWalker", &w, Options{Mode: Recorder})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := "func (r *RecordWalker) Walk(ctx context.Context, root string, visit func(path string) error) (r0 int, r1 error) {\n" +
+		"\tcall := r.Tape.Call(\"Walk\", &root)\n"
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Method != "Walk" ||
+		!strings.Contains(result.Warnings[0].Message, "parameter visit is not recorded") {
+		t.Errorf("ImplWithOptions(...) warned %v, want a warning about parameter visit", result.Warnings)
+	}
+
+	_, err = ImplWithOptions("impl/impl/test_data/panther.Walker", "r *RecordWalker", &bytes.Buffer{}, Options{Mode: Recorder, Strict: true})
+	if _, ok := err.(*UnhandledConstructError); !ok {
+		t.Errorf("ImplWithOptions(...) with Strict == %v, want an *UnhandledConstructError", err)
+	}
+}
+
+func TestImplWithOptions_RecorderFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "r *RecordReader[T]", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.WithChannel", "r *RecordChannel", &UnserializableTypeError{}},
+		{"impl/impl/test_data/panther.WithEveryResolvedType::Compose", "r *RecordComposer", &UnserializableTypeError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Recorder})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package impl
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// retryPath is the import path of the package retry decorators are built on.
+const retryPath = "github.com/ajmesa9891/impl/retry"
+
+// retryMethod is a method of a retry decorator.
+type retryMethod struct {
+	forwardedMethod
+	Attempt string // the name of the method's variable counting its attempts
+	CtxArg  string // the context waited with (e.g., "ctx" or "context.Background()")
+	Failed  string // the results returned when all attempts failed (e.g., "0, err")
+}
+
+var retryTmpl = template.Must(template.New("retry").Parse(
+	"// {{.Type}} passes the calls made to it on to Inner, and attempts again the\n" +
+		"// calls to its methods returning an error that fail, as Policy tells.\n" +
+		"type {{.Type}} struct {\n" +
+		"Inner  {{.InnerType}}\n" +
+		"Policy {{.Pkg}}.Policy\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{if .Err}}" +
+		"for {{.Attempt}} := 1; ; {{.Attempt}}++ {\n" +
+		"if {{.Results}} = {{$.Recv}}.Inner.{{.Name}}({{.Args}}); {{.Err}} == nil {\n" +
+		"return\n" +
+		"}\n" +
+		"if !{{$.Recv}}.Policy.Retry({{.CtxArg}}, {{.Attempt}}, {{.Err}}) {\n" +
+		"return {{.Failed}}\n" +
+		"}\n" +
+		"}\n" +
+		"{{else}}" +
+		"{{if .Out}}return {{end}}{{$.Recv}}.Inner.{{.Name}}({{.Args}})\n" +
+		"{{end}}" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderRetry writes the declaration of the receiver's type as a retry
+// decorator of the interface i, which passes the calls made to it on to an
+// inner implementation, along with its methods. Methods whose last result is
+// an error attempt calls again while they fail and their policy allows it,
+// waiting with their first context.Context parameter, if any, and return
+// zero values along with the last error when all attempts fail. Other
+// methods only pass calls on. Retry decorators are built on the package at
+// retryPath.
+func renderRetry(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Retry); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to retry", i.Name)
+	}
+	if err := checkMembers(i, Retry, "Inner", "Policy"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		InnerType string
+		Pkg       string
+		Methods   []retryMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		InnerType: i.useType(),
+		Pkg:       i.importName(retryPath, "retry"),
+	}
+	// Methods without a context wait with context.Background(), so their
+	// parameters must not hide the package, even before it is imported.
+	for _, m := range forwardedMethods(i, recvName, i.packageName("context", "context")) {
+		rm := retryMethod{forwardedMethod: m}
+		if m.Err != "" {
+			rm.Attempt = freeName("attempt", m.taken)
+			for _, p := range m.In {
+				if isContextParam(p) {
+					rm.CtxArg = p.Name
+					break
+				}
+			}
+			if rm.CtxArg == "" {
+				rm.CtxArg = i.importName("context", "context") + ".Background()"
+			}
+			failed := make([]string, len(m.Out))
+			for j, p := range m.Out[:len(m.Out)-1] {
+				failed[j] = zeroValue(p)
+			}
+			failed[len(m.Out)-1] = m.Err
+			rm.Failed = strings.Join(failed, ", ")
+		}
+		data.Methods = append(data.Methods, rm)
+	}
+	return renderTemplate(retryTmpl, data, w)
+}
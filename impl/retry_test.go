@@ -0,0 +1,123 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Retry(t *testing.T) {
+	var w bytes.Buffer
+	result, err := ImplWithOptions("impl/impl/test_data/panther.Cabinet", "r *RetryCabinet", &w, Options{Mode: Retry})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := `// RetryCabinet passes the calls made to it on to Inner, and attempts again the
+// calls to its methods returning an error that fail, as Policy tells.
+type RetryCabinet struct {
+	Inner  panther.Cabinet
+	Policy retry.Policy
+}
+
+func (r *RetryCabinet) Get(ctx context.Context, key string) (r0 string, r1 error) {
+	for attempt := 1; ; attempt++ {
+		if r0, r1 = r.Inner.Get(ctx, key); r1 == nil {
+			return
+		}
+		if !r.Policy.Retry(ctx, attempt, r1) {
+			return "", r1
+		}
+	}
+}
+
+func (r *RetryCabinet) Put(ctx context.Context, key string, value string) (r0 error) {
+	for attempt := 1; ; attempt++ {
+		if r0 = r.Inner.Put(ctx, key, value); r0 == nil {
+			return
+		}
+		if !r.Policy.Retry(ctx, attempt, r0) {
+			return r0
+		}
+	}
+}
+
+func (r *RetryCabinet) Watch(ctx context.Context, key string) (r0 <-chan string) {
+	return r.Inner.Watch(ctx, key)
+}
+
+func (r *RetryCabinet) Len() (r0 int) {
+	return r.Inner.Len()
+}
+
+`
+	if got := w.String(); got != want {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwant\n%s", got, want)
+	}
+	wantImports := []Import{{Path: "context"}, {Path: "impl/impl/test_data/panther"}, {Path: retryPath}}
+	if !reflect.DeepEqual(result.Imports, wantImports) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, wantImports)
+	}
+}
+
+func TestImplWithOptions_RetryReturnsZeroValues(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Fetcher", "r *RetryFetcher", &w, Options{Mode: Retry})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"\t\tif !r.Policy.Retry(context.Background(), attempt, r6) {\n" +
+			"\t\t\treturn [4]byte{}, struct{ N int }{}, nil, false, 0, \"\", r6\n",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_RetryWaitsWithAnyContext(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Sender", "r *RetrySender", &w, Options{Mode: Retry})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := "\t\tif !r.Policy.Retry(ctx, attempt, r0) {\n"
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+	}
+}
+
+func TestImplWithOptions_RetryRenamesShadowingParameters(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Shadower::Fetch", "r *RetryShadower", &w, Options{Mode: Retry})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := "func (r *RetryShadower) Fetch(context_ string) (r0 error) {\n" +
+		"\tfor attempt := 1; ; attempt++ {\n" +
+		"\t\tif r0 = r.Inner.Fetch(context_); r0 == nil {\n" +
+		"\t\t\treturn\n" +
+		"\t\t}\n" +
+		"\t\tif !r.Policy.Retry(context.Background(), attempt, r0) {\n"
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+	}
+}
+
+func TestImplWithOptions_RetryFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "r *RetryReader[T]", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.Layered", "r *RetryLayered", &InvalidInterfacePathError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Retry})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
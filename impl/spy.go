@@ -0,0 +1,146 @@
+package impl
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// spyMethod is a method of a spy, whose parameters and results are all named
+// so that they can be passed on and recorded.
+type spyMethod struct {
+	Method
+	Args    string     // the arguments passed on to the inner implementation (e.g., "p, opts...")
+	Results string     // the names of the results (e.g., "n, err")
+	Fields  []spyField // the fields of the method's call, one per parameter and result
+}
+
+// spyField is a field of the call of a spy's method, recording one of its
+// parameters or results.
+type spyField struct {
+	Name  string // e.g., "Err"
+	Type  string // e.g., "error", or "[]int" for "...int"
+	Value string // the parameter or result recorded (e.g., "err")
+}
+
+var spyTmpl = template.Must(template.New("spy").Parse(
+	"// {{.Type}} is a spy {{.Interface}}: it records the calls made to it,\n" +
+		"// and passes them on to Inner unless it is nil.\n" +
+		"type {{.Type}} struct {\n" +
+		"// Inner is the implementation the calls are passed on to, if any.\n" +
+		"Inner {{.InnerType}}\n\n" +
+		"mu {{.Sync}}.Mutex\n" +
+		"calls struct {\n" +
+		"{{range .Methods}}{{.Name}} []{{$.Type}}{{.Name}}Call\n{{end}}" +
+		"}\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"if {{$.Recv}}.Inner != nil {\n" +
+		"{{if .Out}}{{.Results}} = {{end}}{{$.Recv}}.Inner.{{.Name}}({{.Args}})\n" +
+		"}\n" +
+		"{{$.Recv}}.mu.Lock()\n" +
+		"{{$.Recv}}.calls.{{.Name}} = append({{$.Recv}}.calls.{{.Name}}, {{$.Type}}{{.Name}}Call{ " +
+		"{{range .Fields}}{{.Name}}: {{.Value}}, {{end}}})\n" +
+		"{{$.Recv}}.mu.Unlock()\n" +
+		"{{if .Out}}return\n{{end}}" +
+		"}\n\n" +
+		"// {{.Name}}Calls returns the calls made to {{.Name}}, in the order they were made.\n" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}Calls() []{{$.Type}}{{.Name}}Call {\n" +
+		"{{$.Recv}}.mu.Lock()\n" +
+		"defer {{$.Recv}}.mu.Unlock()\n" +
+		"return append([]{{$.Type}}{{.Name}}Call(nil), {{$.Recv}}.calls.{{.Name}}...)\n" +
+		"}\n\n" +
+		"// {{.Name}}CallCount returns the number of calls made to {{.Name}}.\n" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}CallCount() int {\n" +
+		"{{$.Recv}}.mu.Lock()\n" +
+		"defer {{$.Recv}}.mu.Unlock()\n" +
+		"return len({{$.Recv}}.calls.{{.Name}})\n" +
+		"}\n\n" +
+		"// {{$.Type}}{{.Name}}Call is a call made to {{$.Type}}.{{.Name}}.\n" +
+		"type {{$.Type}}{{.Name}}Call struct {\n" +
+		"{{range .Fields}}{{.Name}} {{.Type}}\n{{end}}" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderSpy writes the declaration of the receiver's type as a spy of the
+// interface i, which records the calls made to it and passes them on to an
+// inner implementation, along with its methods, the accessors of the calls
+// made to them and the types of those calls.
+func renderSpy(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Spy); err != nil {
+		return err
+	}
+	if !recv.pointer {
+		return NewInvalidReceiverError("spies record the calls made to them, so receiver type %q must be a pointer", recv.typeName)
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to spy on", i.Name)
+	}
+	members := []string{"Inner", "mu", "calls"}
+	for _, m := range i.Methods {
+		members = append(members, m.Name+"Calls", m.Name+"CallCount")
+	}
+	if err := checkMembers(i, Spy, members...); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		Interface string
+		InnerType string
+		Sync      string
+		Methods   []spyMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		Interface: i.Name,
+		InnerType: i.useType(),
+		Sync:      i.importName("sync", "sync"),
+	}
+	for _, m := range i.Methods {
+		reserved := i.reservedNames(m, recvName)
+		taken := takenNames(reserved, m.In, m.Out)
+		m.In = namedParams(m.In, "p", reserved, taken)
+		m.Out = namedParams(m.Out, "r", reserved, taken)
+		data.Methods = append(data.Methods, spyMethod{
+			Method:  m,
+			Args:    callArgs(m.In),
+			Results: paramNames(m.Out),
+			Fields:  spyFields(m),
+		})
+	}
+	return renderTemplate(spyTmpl, data, w)
+}
+
+// spyFields returns the fields of the call of m, which must have named
+// parameters and results: one per parameter and result, named as them but
+// exported (e.g., "Err" for "err"). Variadic parameters are recorded as
+// slices.
+func spyFields(m Method) []spyField {
+	taken := make(map[string]bool)
+	var fields []spyField
+	for _, p := range append(m.In[:len(m.In):len(m.In)], m.Out...) {
+		fields = append(fields, spyField{
+			Name:  freeName(exportedName(p.Name), taken),
+			Type:  sliceType(p),
+			Value: p.Name,
+		})
+	}
+	return fields
+}
+
+// sliceType returns the type of the slice p is if it is variadic (e.g.,
+// "[]int" for "...int"), or its type otherwise.
+func sliceType(p Parameter) string {
+	if p.Variadic() {
+		return "[]" + strings.TrimPrefix(p.Type, "...")
+	}
+	return p.Type
+}
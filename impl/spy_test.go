@@ -0,0 +1,104 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Spy(t *testing.T) {
+	var w bytes.Buffer
+	result, err := ImplWithOptions("io.ReadWriter::Read", "s *SpyReader", &w, Options{Mode: Spy})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := `// SpyReader is a spy io.ReadWriter: it records the calls made to it,
+// and passes them on to Inner unless it is nil.
+type SpyReader struct {
+	// Inner is the implementation the calls are passed on to, if any.
+	Inner io.ReadWriter
+
+	mu    sync.Mutex
+	calls struct {
+		Read []SpyReaderReadCall
+	}
+}
+
+func (s *SpyReader) Read(p []byte) (n int, err error) {
+	if s.Inner != nil {
+		n, err = s.Inner.Read(p)
+	}
+	s.mu.Lock()
+	s.calls.Read = append(s.calls.Read, SpyReaderReadCall{P: p, N: n, Err: err})
+	s.mu.Unlock()
+	return
+}
+
+// ReadCalls returns the calls made to Read, in the order they were made.
+func (s *SpyReader) ReadCalls() []SpyReaderReadCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SpyReaderReadCall(nil), s.calls.Read...)
+}
+
+// ReadCallCount returns the number of calls made to Read.
+func (s *SpyReader) ReadCallCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.calls.Read)
+}
+
+// SpyReaderReadCall is a call made to SpyReader.Read.
+type SpyReaderReadCall struct {
+	P   []byte
+	N   int
+	Err error
+}
+
+`
+	if got := w.String(); got != want {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwant\n%s", got, want)
+	}
+	if want := []Import{{Path: "io"}, {Path: "sync"}}; !reflect.DeepEqual(result.Imports, want) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, want)
+	}
+}
+
+func TestImplWithOptions_SpyNamesItsFields(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.WithUnnamed", "s *SpyRoarer", &w,
+		Options{PkgPath: "impl/impl/test_data/panther", Mode: Spy})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	for _, want := range []string{
+		"\tInner WithUnnamed\n",
+		"\t\tr0 = s.Inner.Roar(p0, p1, p2...)\n",
+		"SpyRoarerRoarCall{P0: p0, P1: p1, P2: p2, R0: r0})",
+		"type SpyRoarerRoarCall struct {\n\tP0 context.Context\n\tP1 string\n\tP2 []int\n\tR0 error\n}",
+		"\t\ts.Inner.Purr(p0, loudness)\n",
+		"type SpyRoarerPurrCall struct {\n\tP0       int\n\tLoudness float64\n}",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+		}
+	}
+}
+
+func TestImplWithOptions_SpyFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "s SpyReader", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.WithHelperNames", "s *SpyScratcher", &InvalidInterfacePathError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Spy})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
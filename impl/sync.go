@@ -0,0 +1,522 @@
+package impl
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// SyncResult describes the changes Sync makes to an implementation of an
+// interface.
+type SyncResult struct {
+	// Files are the files that changed, with their new source. They are
+	// not written by Sync.
+	Files []SyncedFile
+
+	// Added are the methods scaffolding was added for, Updated are the
+	// methods whose parameters and results were changed to match the
+	// interface, and Stale are the methods flagged as no longer in it.
+	Added   []string
+	Updated []string
+	Stale   []string
+
+	// Warnings describe the constructs of the interface that could not be
+	// handled, and the methods that could not be synced.
+	Warnings []Warning
+}
+
+// SyncedFile is a file changed by Sync.
+type SyncedFile struct {
+	Name string
+	Src  []byte
+}
+
+// Sync updates the implementation of the interface at path (see Impl) by
+// the type of receiver, declared in the package opts.PkgPath, after the
+// interface changed. Scaffolding is added for the methods the type does not
+// have, documented as implementing the interface (e.g., "// Read implements
+// io.Reader."). The parameters and results of the methods the type has with
+// a different signature are changed to match the interface, keeping the
+// names the method gives those whose type did not change and leaving its
+// body alone. Methods that are documented as implementing the interface but
+// are no longer in it are flagged with a comment instead of being deleted.
+// If path names a method (e.g., "io.ReadWriter::Read"), only that method is
+// synced; the other methods of the interface are still not flagged.
+//
+// The scaffolding is added to the end of the file declaring the type, and
+// the imports it needs are added to the files changed. opts.Imports and
+// opts.Missing are ignored.
+func Sync(path, receiver string, opts Options) (*SyncResult, error) {
+	recv, err := parseReceiver(receiver)
+	if err != nil {
+		return nil, err
+	}
+	err = recv.validate(opts.PkgPath, opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg, typeName := recv.lookup(opts.PkgPath, opts.Dir)
+	if typeName == nil {
+		return nil, NewInvalidReceiverError("receiver type %q must be declared in package %q to be synced",
+			recv.typeName, opts.PkgPath)
+	}
+	// A method named by path is synced alone, but which methods are stale
+	// is decided by the whole interface.
+	pkgPath, interfaceName, methodName, err := parseImport(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &syncer{
+		path:     pkgPath + "." + interfaceName,
+		receiver: receiver,
+		recv:     recv,
+		opts:     opts,
+		pkg:      pkg,
+		typeName: typeName,
+		fset:     token.NewFileSet(),
+		files:    make(map[string]*syncFile),
+		result:   &SyncResult{},
+	}
+	if err := s.parseFiles(); err != nil {
+		return nil, err
+	}
+	typeFile := s.files[pkg.fset.Position(typeName.Pos()).Filename]
+	if typeFile == nil {
+		return nil, NewInvalidReceiverError("could not parse the file declaring receiver type %q", recv.typeName)
+	}
+	iface, err := s.buildInterface(typeFile)
+	if err != nil {
+		return nil, err
+	}
+	synced, err := filterMethod(iface.Methods, methodName)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range iface.Warnings {
+		if w.Method == "" || methodName == "" || w.Method == methodName {
+			s.result.Warnings = append(s.result.Warnings, w)
+		}
+	}
+	if opts.Strict && len(s.result.Warnings) > 0 {
+		return nil, NewUnhandledConstructError(s.result.Warnings)
+	}
+
+	inInterface := make(map[string]bool, len(iface.Methods))
+	for _, m := range iface.Methods {
+		inInterface[m.Name] = true
+	}
+	for _, m := range synced {
+		if err := s.syncMethod(iface, m, typeFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range s.declaredNames() {
+		if !inInterface[name] {
+			s.flagStale(iface, name)
+		}
+	}
+	return s.result, s.writeFiles()
+}
+
+// syncer syncs the methods of a type with an interface. See Sync.
+type syncer struct {
+	path     string // the interface's path
+	receiver string // the receiver, as given
+	recv     *receiver
+	opts     Options
+	pkg      *typedPackage
+	typeName *types.TypeName
+
+	// fset holds the positions of files, which are parsed with comments.
+	fset  *token.FileSet
+	files map[string]*syncFile
+
+	// declared are the methods declared for the type, by name.
+	declared map[string]*ast.FuncDecl
+
+	result *SyncResult
+}
+
+// syncFile is a file of the type's package, along with the edits to make to
+// its source and the imports they need.
+type syncFile struct {
+	name    string
+	src     []byte
+	file    *ast.File
+	iface   *Interface // built as qualified in the file
+	edits   []syncEdit
+	imports []Import
+}
+
+// syncEdit replaces the source from offset start to end with text.
+type syncEdit struct {
+	start, end int
+	text       string
+}
+
+// edit replaces the source from start to end with text. Text inserted at the
+// same position is inserted in the order it is edited.
+func (f *syncFile) edit(start, end token.Pos, fset *token.FileSet, text string) {
+	f.edits = append(f.edits, syncEdit{fset.Position(start).Offset, fset.Position(end).Offset, text})
+}
+
+// parseFiles parses the files of the type's package, with comments, and
+// finds the methods declared for the type. Files that cannot be parsed are
+// left alone.
+func (s *syncer) parseFiles() error {
+	s.declared = make(map[string]*ast.FuncDecl)
+	for _, name := range append(append([]string{}, s.pkg.GoFiles...), s.pkg.CgoFiles...) {
+		name = filepath.Join(s.pkg.Dir, name)
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		file, err := parser.ParseFile(s.fset, name, src, parser.ParseComments)
+		if err != nil {
+			dl("    skipping file %q, which could not be parsed: %s", name, err)
+			continue
+		}
+		s.files[name] = &syncFile{name: name, src: src, file: file}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && receiverTypeName(fn) == s.recv.typeName {
+				s.declared[fn.Name.Name] = fn
+			}
+		}
+	}
+	return nil
+}
+
+// receiverTypeName returns the name of the base type of fn's receiver, or ""
+// if fn is not a method.
+func receiverTypeName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch index := expr.(type) {
+	case *ast.IndexExpr:
+		expr = index.X
+	case *ast.IndexListExpr:
+		expr = index.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// receiverName returns the name of fn's receiver, or "" if it is unnamed.
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 || len(fn.Recv.List[0].Names) == 0 {
+		return ""
+	}
+	return fn.Recv.List[0].Names[0].Name
+}
+
+// declaredNames returns the names of the methods declared for the type, in
+// the order they are declared.
+func (s *syncer) declaredNames() []string {
+	names := make([]string, 0, len(s.declared))
+	for name := range s.declared {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return s.declared[names[i]].Pos() < s.declared[names[j]].Pos()
+	})
+	return names
+}
+
+// fileOf returns the file declaring fn.
+func (s *syncer) fileOf(fn *ast.FuncDecl) *syncFile {
+	return s.files[s.fset.Position(fn.Pos()).Filename]
+}
+
+// buildInterface builds the interface as qualified in f.
+func (s *syncer) buildInterface(f *syncFile) (*Interface, error) {
+	if f.iface == nil {
+		opts := s.opts
+		opts.Imports = astImports(f.file)
+		opts.Missing = false
+		iface, err := buildInterface(s.path, s.recv, opts)
+		if err != nil {
+			return nil, err
+		}
+		f.iface = iface
+	}
+	return f.iface, nil
+}
+
+// syncMethod adds scaffolding for m to typeFile if the type does not have
+// it, or changes its signature if it is different from m's.
+func (s *syncer) syncMethod(iface *Interface, m Method, typeFile *syncFile) error {
+	obj, _, _ := types.LookupFieldOrMethod(s.typeName.Type(), true, s.pkg.types, m.Name)
+	fn, isMethod := obj.(*types.Func)
+	same := isMethod && (m.Signature == nil || sameSignature(m.Signature, fn.Type().(*types.Signature)))
+
+	if decl, ok := s.declared[m.Name]; ok {
+		if same {
+			return nil
+		}
+		f := s.fileOf(decl)
+		fiface, err := s.buildInterface(f)
+		if err != nil {
+			return err
+		}
+		for _, fm := range fiface.Methods {
+			if fm.Name == m.Name {
+				m = fm
+			}
+		}
+		f.edit(decl.Type.Params.Pos(), decl.Type.End(), s.fset, syncedSignature(m, receiverName(decl), decl.Type))
+		f.imports = mergeImports(f.imports, methodImports(m))
+		s.result.Updated = append(s.result.Updated, m.Name)
+		return nil
+	}
+
+	if same {
+		return nil // promoted from an embedded field
+	}
+	if obj != nil && !isMethod {
+		s.result.Warnings = append(s.result.Warnings, Warning{
+			Pos:     s.pkg.fset.Position(obj.Pos()),
+			Method:  m.Name,
+			Message: fmt.Sprintf("receiver type %s has a field with the method's name, so it was not scaffolded", s.recv.typeName),
+		})
+		return nil
+	}
+	var stub bytes.Buffer
+	fmt.Fprintf(&stub, "\n// %s\n", implementsComment(m.Name, iface.Name))
+	err := renderInterface(&Interface{Methods: []Method{m}}, s.receiver, &stub)
+	if err != nil {
+		return err
+	}
+	end := typeFile.file.FileEnd
+	typeFile.edit(end, end, s.fset, strings.TrimSuffix(stub.String(), "\n"))
+	typeFile.imports = mergeImports(typeFile.imports, methodImports(m))
+	s.result.Added = append(s.result.Added, m.Name)
+	return nil
+}
+
+// flagStale flags the declared method with the given name as no longer in
+// the interface if it is documented as implementing it.
+func (s *syncer) flagStale(iface *Interface, name string) {
+	decl := s.declared[name]
+	if decl.Doc == nil {
+		return
+	}
+	implements, flagged := false, false
+	flag := staleComment(name, iface.Name)
+	for _, c := range decl.Doc.List {
+		implements = implements || strings.TrimSuffix(c.Text, ".") == "// "+strings.TrimSuffix(implementsComment(name, iface.Name), ".")
+		flagged = flagged || c.Text == "// "+flag
+	}
+	if !implements || flagged {
+		return
+	}
+	f := s.fileOf(decl)
+	f.edit(decl.Pos(), decl.Pos(), s.fset, "// "+flag+"\n")
+	s.result.Stale = append(s.result.Stale, name)
+}
+
+// implementsComment documents that method name implements the interface
+// with the given name.
+func implementsComment(name, ifaceName string) string {
+	return fmt.Sprintf("%s implements %s.", name, ifaceName)
+}
+
+// staleComment flags that method name is no longer in the interface with
+// the given name.
+func staleComment(name, ifaceName string) string {
+	return fmt.Sprintf("TODO: %s is no longer a method of %s; remove it if it is not needed.", name, ifaceName)
+}
+
+// syncedSignature writes the parameters and results of m, named as ft, the
+// signature of the method being synced, names them (see reusedNames), or as
+// m names them otherwise. recvName is the name of the method's receiver,
+// which no parameter or result takes.
+func syncedSignature(m Method, recvName string, ft *ast.FuncType) string {
+	taken := make(map[string]bool)
+	if recvName != "" && recvName != "_" {
+		taken[recvName] = true
+	}
+	inNames := reusedNames(m.In, ft.Params, taken)
+	outNames := reusedNames(m.Out, ft.Results, taken)
+	params, _ := syncedParams(m.In, inNames, taken)
+	sig := "(" + params + ")"
+	results, named := syncedParams(m.Out, outNames, taken)
+	switch {
+	case len(m.Out) == 0:
+	case len(m.Out) == 1 && !named:
+		sig += " " + results
+	default:
+		sig += " (" + results + ")"
+	}
+	return sig
+}
+
+// reusedNames returns the names fl gives params, and takes them. A parameter
+// is given the name of the parameter of fl in the same position if their
+// types are the same, or else the name of the first other parameter of fl
+// with its type, so that parameters added to or moved in the interface do
+// not take the names of others. Parameters fl does not name are given "".
+func reusedNames(params []Parameter, fl *ast.FieldList, taken map[string]bool) []string {
+	type oldParam struct{ name, typ string }
+	var old []oldParam
+	if fl != nil {
+		for _, field := range fl.List {
+			typ := types.ExprString(field.Type)
+			if len(field.Names) == 0 {
+				old = append(old, oldParam{"", typ})
+			}
+			for _, name := range field.Names {
+				old = append(old, oldParam{name.Name, typ})
+			}
+		}
+	}
+
+	names := make([]string, len(params))
+	matched := make([]bool, len(params))
+	used := make([]bool, len(old))
+	for i, p := range params {
+		if i < len(old) && sameParamType(old[i].typ, p.Type) {
+			names[i], matched[i], used[i] = old[i].name, true, true
+		}
+	}
+	for i, p := range params {
+		for j := 0; j < len(old) && !matched[i]; j++ {
+			if !used[j] && old[j].name != "" && sameParamType(old[j].typ, p.Type) {
+				names[i], matched[i], used[j] = old[j].name, true, true
+			}
+		}
+	}
+	for _, name := range names {
+		if name != "" && name != "_" {
+			taken[name] = true
+		}
+	}
+	return names
+}
+
+// sameParamType reports whether the parameter types a and b, as written, are
+// the same, taking a variadic parameter's type to be a slice (e.g., "...int"
+// and "[]int").
+func sameParamType(a, b string) bool {
+	slice := func(t string) string {
+		if strings.HasPrefix(t, "...") {
+			return "[]" + t[len("..."):]
+		}
+		return t
+	}
+	return slice(a) == slice(b)
+}
+
+// syncedParams writes params, with the names reused for them (see
+// reusedNames), or their own names otherwise, made free of taken. It reports
+// whether they are named.
+func syncedParams(params []Parameter, names []string, taken map[string]bool) (string, bool) {
+	named := false
+	list := make([]string, len(params))
+	for i, p := range params {
+		list[i] = names[i]
+		if list[i] == "" && p.Name != "" && p.Name != "_" {
+			list[i] = freeName(p.Name, taken)
+		} else if list[i] == "" {
+			list[i] = p.Name
+		}
+		named = named || list[i] != ""
+	}
+	for i, p := range params {
+		if named && list[i] == "" {
+			list[i] = "_" // parameters are either all named or all unnamed
+		}
+		list[i] = strings.TrimSpace(list[i] + " " + p.Type)
+	}
+	return strings.Join(list, ", "), named
+}
+
+// methodImports returns the imports the types of m need.
+func methodImports(m Method) []Import {
+	var imports []Import
+	for _, p := range append(append([]Parameter{}, m.In...), m.Out...) {
+		imports = mergeImports(imports, p.Imports)
+	}
+	return imports
+}
+
+// astImports returns the imports of file.
+func astImports(file *ast.File) []Import {
+	imports := make([]Import, 0, len(file.Imports))
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		imp := Import{Path: path}
+		if spec.Name != nil {
+			imp.Name = spec.Name.Name
+		}
+		imports = append(imports, imp)
+	}
+	return imports
+}
+
+// writeFiles applies the edits to the files that have any, adds the
+// imports they need and formats them into the result.
+func (s *syncer) writeFiles() error {
+	names := make([]string, 0, len(s.files))
+	for name, f := range s.files {
+		if len(f.edits) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := s.files[name]
+		sort.SliceStable(f.edits, func(i, j int) bool { return f.edits[i].start < f.edits[j].start })
+		src, err := f.apply()
+		if err != nil {
+			return fmt.Errorf("could not sync %q: %s", name, err)
+		}
+		s.result.Files = append(s.result.Files, SyncedFile{Name: name, Src: src})
+	}
+	return nil
+}
+
+// apply returns the source of f with its edits and imports.
+func (f *syncFile) apply() ([]byte, error) {
+	// Edits are applied from the end, so that their offsets stay valid.
+	src := append([]byte{}, f.src...)
+	for i := len(f.edits) - 1; i >= 0; i-- {
+		e := f.edits[i]
+		src = append(src[:e.start], append([]byte(e.text), src[e.end:]...)...)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, f.name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for _, imp := range f.imports {
+		astutil.AddNamedImport(fset, file, imp.Name, imp.Path)
+	}
+	var w bytes.Buffer
+	if err := format.Node(&w, fset, file); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
@@ -0,0 +1,377 @@
+package impl
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSync(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		wantAdded     []string
+		wantUpdated   []string
+		wantStale     []string
+		wantSource    string
+	}{
+		{
+			"impl/impl/test_data/panther.ClawReadWriter",
+			[]string{"Read", "Write"},
+			[]string{"Puncture"},
+			[]string{"Scratch"},
+			`package panther
+
+import "strings"
+
+// OldClaw implements an older version of ClawReadWriter.
+type OldClaw struct {
+	name strings.Builder
+}
+
+// Hardness implements ClawReadWriter.
+func (o *OldClaw) Hardness() int {
+	return 1
+}
+
+// Puncture implements ClawReadWriter.
+func (o *OldClaw) Puncture(force int) {
+	return force > 0
+}
+
+// Scratch implements ClawReadWriter.
+// TODO: Scratch is no longer a method of ClawReadWriter; remove it if it is not needed.
+func (o *OldClaw) Scratch() {}
+
+// Close is not part of ClawReadWriter.
+func (o *OldClaw) Close() error {
+	return nil
+}
+
+// Read implements ClawReadWriter.
+func (o *OldClaw) Read(p []byte) (n int, err error) {
+	panic("TODO: implement this method")
+}
+
+// Write implements ClawReadWriter.
+func (o *OldClaw) Write(p []byte) (n int, err error) {
+	panic("TODO: implement this method")
+}
+`,
+		},
+		{
+			"impl/impl/test_data/panther.WithMap",
+			[]string{"TakeGiveMap"},
+			nil,
+			nil,
+			`package panther
+
+import (
+	"io"
+	"strings"
+)
+
+// OldClaw implements an older version of ClawReadWriter.
+type OldClaw struct {
+	name strings.Builder
+}
+
+// Hardness implements ClawReadWriter.
+func (o *OldClaw) Hardness() int {
+	return 1
+}
+
+// Puncture implements ClawReadWriter.
+func (o *OldClaw) Puncture(force int, angle float64) bool {
+	return force > 0
+}
+
+// Scratch implements ClawReadWriter.
+func (o *OldClaw) Scratch() {}
+
+// Close is not part of ClawReadWriter.
+func (o *OldClaw) Close() error {
+	return nil
+}
+
+// TakeGiveMap implements WithMap.
+func (o *OldClaw) TakeGiveMap(theMap map[string]io.Reader) map[int]string {
+	panic("TODO: implement this method")
+}
+`,
+		},
+	}
+
+	opts := Options{PkgPath: "impl/impl/test_data/panther"}
+	for _, c := range cases {
+		got, err := Sync(c.interfacePath, "o *OldClaw", opts)
+		if err != nil {
+			t.Errorf("Sync(%q, ...) failed: %s", c.interfacePath, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Added, c.wantAdded) ||
+			!reflect.DeepEqual(got.Updated, c.wantUpdated) ||
+			!reflect.DeepEqual(got.Stale, c.wantStale) {
+			t.Errorf("Sync(%q, ...) added %q, updated %q and flagged %q, want %q, %q and %q",
+				c.interfacePath, got.Added, got.Updated, got.Stale, c.wantAdded, c.wantUpdated, c.wantStale)
+		}
+		if len(got.Files) != 1 || filepath.Base(got.Files[0].Name) != "old_claw.go" {
+			t.Errorf("Sync(%q, ...) changed files %+v, want old_claw.go", c.interfacePath, got.Files)
+			continue
+		}
+		if src := string(got.Files[0].Src); src != c.wantSource {
+			t.Errorf("Sync(%q, ...) == \n\"%s\"\n, wanted: \n\"%s\"\n", c.interfacePath, src, c.wantSource)
+		}
+	}
+}
+
+func TestSync_IsIdempotent(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOTOOLCHAIN", "local")
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod": "module example.com/claws\n\ngo 1.21\n",
+		"claws.go": `package claws
+
+import "io"
+
+type Claw interface {
+	io.Reader
+	Puncture(strength int) (ok bool)
+}
+`,
+		"impl.go": `package claws
+
+type Cat struct{}
+
+// Puncture implements Claw.
+func (c Cat) Puncture(force int, angle float64) bool {
+	return force > 0
+}
+
+// Scratch implements Claw.
+func (c Cat) Scratch() {}
+`,
+	})
+
+	opts := Options{Dir: root, PkgPath: "example.com/claws"}
+	first, err := Sync("example.com/claws.Claw", "c Cat", opts)
+	if err != nil {
+		t.Fatalf("Sync(...) failed: %s", err)
+	}
+	if len(first.Files) != 1 {
+		t.Fatalf("Sync(...) changed %d files, want 1", len(first.Files))
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "", first.Files[0].Src, 0); err != nil {
+		t.Fatalf("Sync(...) wrote source that does not parse: %s\n%s", err, first.Files[0].Src)
+	}
+	if !strings.Contains(string(first.Files[0].Src), "func (c Cat) Puncture(force int) (ok bool) {") {
+		t.Errorf("Sync(...) did not keep the parameter names of Puncture:\n%s", first.Files[0].Src)
+	}
+	if err := os.WriteFile(first.Files[0].Name, first.Files[0].Src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Sync("example.com/claws.Claw", "c Cat", opts)
+	if err != nil {
+		t.Fatalf("Sync(...) failed the second time: %s", err)
+	}
+	if len(second.Files)+len(second.Added)+len(second.Updated)+len(second.Stale) > 0 {
+		t.Errorf("Sync(...) changed %+v the second time, want no changes", second)
+	}
+}
+
+func TestSync_FlagsStaleScaffolding(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOTOOLCHAIN", "local")
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod": "module example.com/claws\n\ngo 1.21\n",
+		"claws.go": `package claws
+
+type Claw interface {
+	Hardness() int
+	Scratch()
+}
+`,
+		"impl.go": "package claws\n\ntype Cat struct{}\n",
+	})
+	opts := Options{Dir: root, PkgPath: "example.com/claws"}
+	added, err := Sync("example.com/claws.Claw", "c Cat", opts)
+	if err != nil {
+		t.Fatalf("Sync(...) failed precondition: %s", err)
+	}
+	if len(added.Files) != 1 {
+		t.Fatalf("Sync(...) changed %d files, want 1", len(added.Files))
+	}
+	if err := os.WriteFile(added.Files[0].Name, added.Files[0].Src, 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, root, map[string]string{
+		"claws.go": "package claws\n\ntype Claw interface {\n\tHardness() int\n}\n",
+	})
+
+	got, err := Sync("example.com/claws.Claw", "c Cat", opts)
+	if err != nil {
+		t.Fatalf("Sync(...) failed: %s", err)
+	}
+	if want := []string{"Scratch"}; !reflect.DeepEqual(got.Stale, want) {
+		t.Errorf("Sync(...) flagged %q, want %q", got.Stale, want)
+	}
+}
+
+func TestSync_Method(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOTOOLCHAIN", "local")
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod": "module example.com/claws\n\ngo 1.21\n",
+		"claws.go": `package claws
+
+type Claw interface {
+	Hardness() float64
+	Scratch()
+	Sharpen()
+}
+`,
+		"impl.go": `package claws
+
+type Cat struct{}
+
+// Hardness implements Claw.
+func (c Cat) Hardness() int {
+	return 0
+}
+
+// Puncture implements Claw.
+func (c Cat) Puncture() {}
+
+// Scratch implements Claw.
+func (c Cat) Scratch() {}
+`,
+	})
+
+	got, err := Sync("example.com/claws.Claw::Hardness", "c Cat", Options{Dir: root, PkgPath: "example.com/claws"})
+	if err != nil {
+		t.Fatalf("Sync(...) failed: %s", err)
+	}
+	if want := []string{"Hardness"}; !reflect.DeepEqual(got.Updated, want) {
+		t.Errorf("Sync(...) updated %q, want %q", got.Updated, want)
+	}
+	if len(got.Added) > 0 {
+		t.Errorf("Sync(...) added %q, want only Hardness synced", got.Added)
+	}
+	if want := []string{"Puncture"}; !reflect.DeepEqual(got.Stale, want) {
+		t.Errorf("Sync(...) flagged %q, want %q", got.Stale, want)
+	}
+}
+
+func TestSync_AddsLeadingParameter(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOTOOLCHAIN", "local")
+
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/claws\n\ngo 1.21\n",
+		"claws.go": `package claws
+
+type Ctx struct{}
+
+type Reader interface {
+	Read(ctx Ctx, p []byte) (n int, err error)
+}
+`,
+		"impl.go": `package claws
+
+type R struct{}
+
+// Read implements Reader.
+func (r *R) Read(p []byte) (n int, err error) {
+	return len(p), nil
+}
+`,
+	}
+	writeTree(t, root, files)
+
+	got, err := Sync("example.com/claws.Reader", "r *R", Options{Dir: root, PkgPath: "example.com/claws"})
+	if err != nil {
+		t.Fatalf("Sync(...) failed: %s", err)
+	}
+	if len(got.Files) != 1 {
+		t.Fatalf("Sync(...) changed %d files, want 1", len(got.Files))
+	}
+	src := string(got.Files[0].Src)
+	if want := "func (r *R) Read(ctx Ctx, p []byte) (n int, err error) {"; !strings.Contains(src, want) {
+		t.Errorf("Sync(...) wrote\n%s\nwhich does not contain\n%s", src, want)
+	}
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, f := range []struct{ name, src string }{{"claws.go", files["claws.go"]}, {"impl.go", src}} {
+		file, err := parser.ParseFile(fset, f.name, f.src, 0)
+		if err != nil {
+			t.Fatalf("Sync(...) wrote source that does not parse: %s\n%s", err, src)
+		}
+		parsed = append(parsed, file)
+	}
+	if _, err := new(types.Config).Check("example.com/claws", fset, parsed, nil); err != nil {
+		t.Errorf("Sync(...) wrote source that does not type-check: %s\n%s", err, src)
+	}
+}
+
+func TestSyncedSignature(t *testing.T) {
+	cases := []struct {
+		method string // declared in the interface
+		decl   string // declared by the type
+		want   string
+	}{
+		{"M(a int)", "M(b int)", "(b int)"},
+		{"M(a int)", "M(int)", "(a int)"},
+		{"M(int, string)", "M(a int)", "(a int, _ string)"},
+		{"M(a, b int) error", "M(x int) (err error)", "(x int, b int) (err error)"},
+		{"M() (int, error)", "M() int", "() (int, error)"},
+		{"M(f func(a int) error) func() error", "M()", "(f func(a int) error) func() error"},
+		{"M(xs ...int)", "M(ys []int)", "(ys ...int)"},
+		{"M()", "M(a int) bool", "()"},
+		{"M(ctx context.Context, p []byte)", "M(p []byte)", "(ctx context.Context, p []byte)"},
+		{"M(b []byte, n int)", "M(n int, b []byte)", "(b []byte, n int)"},
+		{"M(p context.Context, b []byte)", "M(p []byte)", "(p_ context.Context, p []byte)"},
+		{"M(a string) int", "M(a int) (a2 int)", "(a string) (a2 int)"},
+		{"M(r int) (err error)", "M(int)", "(r_ int) (err error)"},
+	}
+	for _, c := range cases {
+		m := parseMethod(t, c.method)
+		decl := parseMethod(t, c.decl)
+		fn := decl.Type.(*ast.FuncType)
+		in := buildParams(m.Type.(*ast.FuncType).Params, nil, newQualifier("", nil))
+		out := buildParams(m.Type.(*ast.FuncType).Results, nil, newQualifier("", nil))
+		if got := syncedSignature(NewMethod("M", in, out), "r", fn); got != c.want {
+			t.Errorf("syncedSignature(%q, %q) == %q, want %q", c.method, c.decl, got, c.want)
+		}
+	}
+}
+
+// parseMethod parses the method declared by an interface as s.
+func parseMethod(t *testing.T, s string) *ast.Field {
+	expr, err := parser.ParseExpr("interface{ " + s + " }")
+	if err != nil {
+		t.Fatalf("parseMethod(%q) failed precondition: %s", s, err)
+	}
+	return expr.(*ast.InterfaceType).Methods.List[0]
+}
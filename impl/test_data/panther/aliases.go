@@ -0,0 +1,23 @@
+package panther
+
+import (
+	stdio "io"
+
+	m "ultimatesoftware.com/accountstore/models"
+	u "ultimatesoftware.com/accountstore/utils"
+)
+
+type WithAliases interface {
+	GetAccounts(tenantId string, opts *u.QueryOpts) ([]m.AccountSummary, error)
+}
+
+type AliasedEmbedded interface {
+	stdio.ReadCloser
+	stdio.Closer
+	error
+	Reset(r stdio.Reader)
+}
+
+type UnresolvedEmbedded interface {
+	m.Account
+}
@@ -1,7 +1,11 @@
 package panther
 
 import (
+	"context"
+	htmltemplate "html/template"
 	"io"
+	"text/template"
+	"time"
 
 	"ultimatesoftware.com/accountstore/models"
 	"ultimatesoftware.com/accountstore/utils"
@@ -33,11 +37,59 @@ type WithEllipsis interface {
 	TakeEllipsis(several ...int) int
 }
 
+type WithUnnamed interface {
+	Roar(context.Context, string, ...int) error
+	Purr(_ int, loudness float64)
+}
+
+type Finisher interface {
+	Finish()
+}
+
+type WithHelperNames interface {
+	Scratch() error
+	ScratchFunc()
+	ScratchCalls() int
+}
+
+type Layered interface {
+	Inner() io.Reader
+}
+
+type Cabinet interface {
+	Get(ctx context.Context, key string) (string, error)
+	Put(ctx context.Context, key, value string) error
+	Watch(ctx context.Context, key string) <-chan string
+	Len() int
+}
+
+type Fetcher interface {
+	Fetch(url string) ([4]byte, struct{ N int }, *io.PipeReader, bool, float64, string, error)
+}
+
+type Walker interface {
+	Walk(ctx context.Context, root string, visit func(path string) error) (int, error)
+}
+
 type WithStars interface {
 	GetAccounts(tenantId string, opts *utils.QueryOpts) ([]models.AccountSummary, error)
 	GetTenants(tenantId string, filters *utils.QueryOpts, recursive bool) ([]models.TenantSummary, error)
 }
 
+type WithEveryType interface {
+	Compose(a [4]models.AccountSummary, b <-chan models.AccountSummary, c chan<- *models.AccountSummary) (interface{ Get() models.AccountSummary }, map[string][2]models.AccountSummary)
+	Visit(fn func(models.AccountSummary, int) (bool, error), s struct{ A models.AccountSummary }) *[]models.AccountSummary
+}
+
+type WithEveryResolvedType interface {
+	Compose(a [4]byte, b <-chan io.Reader, c chan<- *io.PipeReader) (interface{ Read() io.Reader }, map[string][2]io.Reader)
+	Visit(fn func(io.Reader, int) (bool, error), s struct{ R io.Reader }) *[]io.Reader
+}
+
+type WithSameNamedPackages interface {
+	Convert(t *template.Template) *htmltemplate.Template
+}
+
 type Type interface {
 	Align() int
 
@@ -97,3 +149,27 @@ type Type interface {
 
 	Out(i int) Type
 }
+
+type Unboxer[T any] interface {
+	Unbox(b models.Box[T]) T
+}
+
+type Boxes[K comparable, V any] interface {
+	Unboxer[V]
+	Box(key K) (models.Box[V], error)
+}
+
+type Namer interface {
+	Names(err string, r int, f ...string) (w bool, e error)
+}
+
+type Sender interface {
+	Send(to string, ctx context.Context, msg []byte) error
+}
+
+type Shadower interface {
+	At(ctx context.Context, time time.Time) (int, error)
+	Fetch(context string) error
+	Notify(errors []error) error
+	Sleep(time int) error
+}
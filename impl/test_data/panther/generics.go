@@ -0,0 +1,30 @@
+package panther
+
+import "io"
+
+type Keyed[K comparable] interface {
+	Keys() []K
+}
+
+type Store[K comparable, V any] interface {
+	Keyed[K]
+	Get(key K) (V, bool)
+	Set(key K, value V)
+	Each(fn func(K, V) bool)
+}
+
+type Pair[T any] interface {
+	Swap(a, b T) (T, T)
+}
+
+type ReaderStore = Store[string, io.Reader]
+
+type List[T any] struct {
+	items []T
+}
+
+type IntStringer interface {
+	~int | ~int64
+	comparable
+	String() string
+}
@@ -0,0 +1,63 @@
+package panther
+
+import "io"
+
+type ClawReadWriter interface {
+	Clawable
+	io.ReadWriter
+}
+
+type Sharpener struct{}
+
+func (s Sharpener) Hardness() int {
+	return 0
+}
+
+// HalfClaw has Hardness promoted from Sharpener, a Read method with the
+// wrong signature and a Write field.
+type HalfClaw struct {
+	Sharpener
+	Write func(p []byte) (n int, err error)
+}
+
+func (h *HalfClaw) Read(p []byte) int {
+	return 0
+}
+
+type FullClaw struct {
+	HalfClaw
+}
+
+func (f FullClaw) Puncture(strength int) {}
+
+func (f *FullClaw) Read(p []byte) (n int, err error) {
+	return 0, nil
+}
+
+func (f *FullClaw) Write(p []byte) (n int, err error) {
+	return 0, nil
+}
+
+type NearClaw struct {
+	Write int
+}
+
+func (n *NearClaw) Hardness() int {
+	return 0
+}
+
+func (n NearClaw) puncture(strength int) {}
+
+func (n NearClaw) Read(p []int) (int, error) {
+	return 0, nil
+}
+
+type Poised interface {
+	Élan() int
+}
+
+type NearPoise struct{}
+
+func (n NearPoise) élan() int {
+	return 0
+}
@@ -0,0 +1,26 @@
+package panther
+
+import "strings"
+
+// OldClaw implements an older version of ClawReadWriter.
+type OldClaw struct {
+	name strings.Builder
+}
+
+// Hardness implements ClawReadWriter.
+func (o *OldClaw) Hardness() int {
+	return 1
+}
+
+// Puncture implements ClawReadWriter.
+func (o *OldClaw) Puncture(force int, angle float64) bool {
+	return force > 0
+}
+
+// Scratch implements ClawReadWriter.
+func (o *OldClaw) Scratch() {}
+
+// Close is not part of ClawReadWriter.
+func (o *OldClaw) Close() error {
+	return nil
+}
@@ -0,0 +1,95 @@
+package impl
+
+import (
+	"io"
+	"text/template"
+)
+
+// timeoutMethod is a method of a timeout decorator.
+type timeoutMethod struct {
+	forwardedMethod
+
+	// Timeout, Ok and Cancel name the variables of the method, so that they
+	// do not shadow its parameters.
+	Timeout, Ok, Cancel string
+}
+
+var timeoutTmpl = template.Must(template.New("timeout").Parse(
+	"// {{.Type}} passes the calls made to it on to Inner, with the timeout in\n" +
+		"// Timeouts for their method, if any, applied to their context. Calls whose\n" +
+		"// context is done return its error instead, if their method returns an\n" +
+		"// error.\n" +
+		"type {{.Type}} struct {\n" +
+		"Inner    {{.InnerType}}\n" +
+		"Timeouts map[string]{{.Time}}.Duration // by method name (e.g., \"{{.Example}}\")\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{if .Ctx}}" +
+		"if {{.Timeout}}, {{.Ok}} := {{$.Recv}}.Timeouts[\"{{.Name}}\"]; {{.Ok}} {\n" +
+		"var {{.Cancel}} {{$.Context}}.CancelFunc\n" +
+		"{{.Ctx}}, {{.Cancel}} = {{$.Context}}.WithTimeout({{.Ctx}}, {{.Timeout}})\n" +
+		"defer {{.Cancel}}()\n" +
+		"}\n" +
+		"{{if .Err}}" +
+		"if {{.Err}} = {{.Ctx}}.Err(); {{.Err}} != nil {\n" +
+		"return\n" +
+		"}\n" +
+		"{{end}}" +
+		"{{end}}" +
+		"{{if .Out}}return {{end}}{{$.Recv}}.Inner.{{.Name}}({{.Args}})\n" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderTimeout writes the declaration of the receiver's type as a timeout
+// decorator of the interface i, which passes the calls made to it on to an
+// inner implementation, along with its methods. Methods whose first
+// parameter is a context.Context apply the timeout configured for them to
+// it, and, if their last result is an error, return the context's error
+// instead of passing calls on when it is done. Other methods only pass calls
+// on.
+func renderTimeout(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Timeout); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to time out", i.Name)
+	}
+	if err := checkMembers(i, Timeout, "Inner", "Timeouts"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		InnerType string
+		Example   string
+		Time      string
+		Context   string
+		Methods   []timeoutMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		InnerType: i.useType(),
+		Example:   i.Methods[0].Name,
+		Time:      i.importName("time", "time"),
+	}
+	for _, m := range forwardedMethods(i, recvName) {
+		tm := timeoutMethod{forwardedMethod: m}
+		if m.Ctx != "" {
+			if data.Context == "" {
+				data.Context = i.importName("context", "context")
+			}
+			tm.Timeout = freeName("timeout", m.taken)
+			tm.Ok = freeName("ok", m.taken)
+			tm.Cancel = freeName("cancel", m.taken)
+		}
+		data.Methods = append(data.Methods, tm)
+	}
+	return renderTemplate(timeoutTmpl, data, w)
+}
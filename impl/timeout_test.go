@@ -0,0 +1,86 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestImplWithOptions_Timeout(t *testing.T) {
+	var w bytes.Buffer
+	result, err := ImplWithOptions("impl/impl/test_data/panther.Cabinet", "t *TimeoutCabinet", &w, Options{Mode: Timeout})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := `// TimeoutCabinet passes the calls made to it on to Inner, with the timeout in
+// Timeouts for their method, if any, applied to their context. Calls whose
+// context is done return its error instead, if their method returns an
+// error.
+type TimeoutCabinet struct {
+	Inner    panther.Cabinet
+	Timeouts map[string]time.Duration // by method name (e.g., "Get")
+}
+
+func (t *TimeoutCabinet) Get(ctx context.Context, key string) (r0 string, r1 error) {
+	if timeout, ok := t.Timeouts["Get"]; ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if r1 = ctx.Err(); r1 != nil {
+		return
+	}
+	return t.Inner.Get(ctx, key)
+}
+
+func (t *TimeoutCabinet) Put(ctx context.Context, key string, value string) (r0 error) {
+	if timeout, ok := t.Timeouts["Put"]; ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if r0 = ctx.Err(); r0 != nil {
+		return
+	}
+	return t.Inner.Put(ctx, key, value)
+}
+
+func (t *TimeoutCabinet) Watch(ctx context.Context, key string) (r0 <-chan string) {
+	if timeout, ok := t.Timeouts["Watch"]; ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return t.Inner.Watch(ctx, key)
+}
+
+func (t *TimeoutCabinet) Len() (r0 int) {
+	return t.Inner.Len()
+}
+
+`
+	if got := w.String(); got != want {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwant\n%s", got, want)
+	}
+	wantImports := []Import{{Path: "context"}, {Path: "impl/impl/test_data/panther"}, {Path: "time"}}
+	if !reflect.DeepEqual(result.Imports, wantImports) {
+		t.Errorf("ImplWithOptions(...) imports %v, want %v", result.Imports, wantImports)
+	}
+}
+
+func TestImplWithOptions_TimeoutFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "t *TimeoutReader[T]", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.Layered", "t *TimeoutLayered", &InvalidInterfacePathError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Timeout})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package impl
+
+import (
+	"go/types"
+	"io"
+	"text/template"
+)
+
+// forwardedMethod is a method of a decorator, whose parameters and results
+// are all named so that they can be passed on to the inner implementation it
+// decorates.
+type forwardedMethod struct {
+	Method
+	Args    string // the arguments passed on to the inner implementation (e.g., "p, opts...")
+	Results string // the names of the results (e.g., "n, err")
+	Ctx     string // the name of its first parameter if it is a context.Context, or ""
+	Err     string // the name of its last result if it is an error, or ""
+
+	// taken are the names the method's locals must not shadow.
+	taken map[string]bool
+}
+
+// forwardedMethods returns the methods of i as methods of a decorator whose
+// receiver is named recvName, and whose bodies refer to the packages named
+// pkgNames. Unnamed parameters and results are named after their position
+// (e.g., "p0" and "r0"), and those that would hide the receiver or a package
+// are renamed (see reservedNames).
+func forwardedMethods(i *Interface, recvName string, pkgNames ...string) []forwardedMethod {
+	methods := make([]forwardedMethod, len(i.Methods))
+	for j, m := range i.Methods {
+		reserved := i.reservedNames(m, recvName, pkgNames...)
+		taken := takenNames(reserved, m.In, m.Out)
+		m.In = namedParams(m.In, "p", reserved, taken)
+		m.Out = namedParams(m.Out, "r", reserved, taken)
+		methods[j] = forwardedMethod{
+			Method:  m,
+			Args:    callArgs(m.In),
+			Results: paramNames(m.Out),
+			taken:   taken,
+		}
+		if len(m.In) > 0 && isContextParam(m.In[0]) {
+			methods[j].Ctx = m.In[0].Name
+		}
+		if len(m.Out) > 0 && isErrorParam(m.Out[len(m.Out)-1]) {
+			methods[j].Err = m.Out[len(m.Out)-1].Name
+		}
+	}
+	return methods
+}
+
+// isContextParam reports whether p is a context.Context.
+func isContextParam(p Parameter) bool {
+	if t := paramType(p); t != nil {
+		return isContext(t)
+	}
+	return p.Type == "context.Context"
+}
+
+// isErrorParam reports whether p is an error.
+func isErrorParam(p Parameter) bool {
+	if t := paramType(p); t != nil {
+		return types.Identical(t, types.Universe.Lookup("error").Type())
+	}
+	return p.Type == "error"
+}
+
+var wrapperTmpl = template.Must(template.New("wrapper").Parse(
+	"// {{.Type}} passes the calls made to it on to Inner. Embed it to override\n" +
+		"// only some of the methods of {{.Interface}}.\n" +
+		"type {{.Type}} struct {\n" +
+		"Inner {{.InnerType}}\n" +
+		"}\n\n" +
+		"{{range .Methods}}" +
+		"func ({{$.Recv}} {{$.Receiver}}) {{.Name}}" +
+		"({{range .In}}{{.Name}} {{.Type}}, {{end}}) " +
+		"{{if .Out}}({{range .Out}}{{.Name}} {{.Type}}, {{end}}){{end}} {\n" +
+		"{{if .Out}}return {{end}}{{$.Recv}}.Inner.{{.Name}}({{.Args}})\n" +
+		"}\n\n" +
+		"{{end}}"))
+
+// renderWrapper writes the declaration of the receiver's type as a wrapper
+// of the interface i, which passes the calls made to it on to an inner
+// implementation, along with its methods.
+func renderWrapper(i *Interface, recv *receiver, w io.Writer) error {
+	if err := recv.validateDeclared(Wrapper); err != nil {
+		return err
+	}
+	if len(i.Methods) == 0 {
+		return NewInvalidInterfacePathError("interface %s has no methods to wrap", i.Name)
+	}
+	if err := checkMembers(i, Wrapper, "Inner"); err != nil {
+		return err
+	}
+
+	recvName := recv.varName()
+	data := struct {
+		Type      string
+		Recv      string
+		Receiver  string
+		Interface string
+		InnerType string
+		Methods   []forwardedMethod
+	}{
+		Type:      recv.typeName,
+		Recv:      recvName,
+		Receiver:  recv.typeString(),
+		Interface: i.Name,
+		InnerType: i.useType(),
+		Methods:   forwardedMethods(i, recvName),
+	}
+	return renderTemplate(wrapperTmpl, data, w)
+}
@@ -0,0 +1,95 @@
+package impl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImplWithOptions_Wrapper(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          string
+	}{
+		{
+			"io.ReadWriter",
+			"w ReadWriterWrapper",
+			`// ReadWriterWrapper passes the calls made to it on to Inner. Embed it to override
+// only some of the methods of io.ReadWriter.
+type ReadWriterWrapper struct {
+	Inner io.ReadWriter
+}
+
+func (w ReadWriterWrapper) Read(p []byte) (n int, err error) {
+	return w.Inner.Read(p)
+}
+
+func (w ReadWriterWrapper) Write(p []byte) (n int, err error) {
+	return w.Inner.Write(p)
+}
+
+`,
+		},
+		{
+			"impl/impl/test_data/panther.WithUnnamed",
+			"*RoarerWrapper",
+			`// RoarerWrapper passes the calls made to it on to Inner. Embed it to override
+// only some of the methods of panther.WithUnnamed.
+type RoarerWrapper struct {
+	Inner panther.WithUnnamed
+}
+
+func (r *RoarerWrapper) Roar(p0 context.Context, p1 string, p2 ...int) (r0 error) {
+	return r.Inner.Roar(p0, p1, p2...)
+}
+
+func (r *RoarerWrapper) Purr(p0 int, loudness float64) {
+	r.Inner.Purr(p0, loudness)
+}
+
+`,
+		},
+	}
+	for _, c := range cases {
+		var w bytes.Buffer
+		if _, err := ImplWithOptions(c.interfacePath, c.receiver, &w, Options{Mode: Wrapper}); err != nil {
+			t.Errorf("ImplWithOptions(%q, %q, ...) failed: %s", c.interfacePath, c.receiver, err)
+			continue
+		}
+		if got := w.String(); got != c.want {
+			t.Errorf("ImplWithOptions(%q, %q, ...) wrote\n%s\nwant\n%s", c.interfacePath, c.receiver, got, c.want)
+		}
+	}
+}
+
+func TestImplWithOptions_WrapperRenamesShadowingParameters(t *testing.T) {
+	var w bytes.Buffer
+	_, err := ImplWithOptions("impl/impl/test_data/panther.Shadower::At", "w *WrapperShadower", &w, Options{Mode: Wrapper})
+	if err != nil {
+		t.Fatalf("ImplWithOptions(...) failed: %s", err)
+	}
+	want := "func (w *WrapperShadower) At(ctx context.Context, time_ time.Time) (r0 int, r1 error) {\n" +
+		"\treturn w.Inner.At(ctx, time_)\n"
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("ImplWithOptions(...) wrote\n%s\nwhich does not contain\n%s", w.String(), want)
+	}
+}
+
+func TestImplWithOptions_WrapperFails(t *testing.T) {
+	cases := []struct {
+		interfacePath string
+		receiver      string
+		want          error
+	}{
+		{"io.Reader", "w *ReaderWrapper[T]", &InvalidReceiverError{}},
+		{"impl/impl/test_data/panther.Layered", "w *LayeredWrapper", &InvalidInterfacePathError{}},
+	}
+	for _, c := range cases {
+		_, err := ImplWithOptions(c.interfacePath, c.receiver, &bytes.Buffer{}, Options{Mode: Wrapper})
+		if reflect.TypeOf(err) != reflect.TypeOf(c.want) {
+			t.Errorf("ImplWithOptions(%q, %q, ...) == %v, want a %T", c.interfacePath, c.receiver, err, c.want)
+		}
+	}
+}
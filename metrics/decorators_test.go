@@ -0,0 +1,58 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/ajmesa9891/impl/metrics"
+)
+
+type Roarer interface {
+	Roar(context.Context, string, ...int) error
+	Purr(_ int, loudness float64)
+}
+
+// MetricsReadWriter and MetricsRoarer are generated by goimpl -mode=metrics,
+// and package impl's tests fail if it would now generate something else.
+
+// MetricsReadWriter records the calls made to it to Recorder, which must not be
+// nil, and passes them on to Inner.
+type MetricsReadWriter struct {
+	Inner    io.ReadWriter
+	Recorder metrics.Recorder
+}
+
+func (m *MetricsReadWriter) Read(p []byte) (n int, err error) {
+	start := time.Now()
+	n, err = m.Inner.Read(p)
+	m.Recorder.Record("Read", time.Since(start), err)
+	return
+}
+
+func (m *MetricsReadWriter) Write(p []byte) (n int, err error) {
+	start := time.Now()
+	n, err = m.Inner.Write(p)
+	m.Recorder.Record("Write", time.Since(start), err)
+	return
+}
+
+// MetricsRoarer records the calls made to it to Recorder, which must not be
+// nil, and passes them on to Inner.
+type MetricsRoarer struct {
+	Inner    Roarer
+	Recorder metrics.Recorder
+}
+
+func (m *MetricsRoarer) Roar(p0 context.Context, p1 string, p2 ...int) (r0 error) {
+	start := time.Now()
+	r0 = m.Inner.Roar(p0, p1, p2...)
+	m.Recorder.Record("Roar", time.Since(start), r0)
+	return
+}
+
+func (m *MetricsRoarer) Purr(p0 int, loudness float64) {
+	start := time.Now()
+	m.Inner.Purr(p0, loudness)
+	m.Recorder.Record("Purr", time.Since(start), nil)
+}
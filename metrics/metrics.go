@@ -0,0 +1,120 @@
+// Package metrics records the calls made to the metrics decorators written by
+// impl (see impl.Metrics). Decorators record each call to a Recorder, which
+// can be backed by any metrics library; Expvar publishes them through the
+// standard expvar package.
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+	"time"
+)
+
+// Recorder records the calls made to the methods of a decorator.
+type Recorder interface {
+	// Record records a call to method that took duration and failed with
+	// err, unless it is nil.
+	Record(method string, duration time.Duration, err error)
+}
+
+// Expvar is a Recorder that publishes, for each method, the number of calls
+// made to it, how many of them failed, and a histogram of their latency.
+// It is safe for concurrent use.
+type Expvar struct {
+	vars *expvar.Map
+
+	mu      sync.Mutex
+	methods map[string]*methodVars
+}
+
+// methodVars are the variables published for a method.
+type methodVars struct {
+	calls, errors expvar.Int
+	latency       Histogram
+}
+
+// NewExpvar returns an Expvar that publishes the metrics of the methods as
+// an expvar.Map named name (e.g., "users.Repository"), which holds a map
+// per method with its "calls", "errors" and "latency". Like expvar.Publish,
+// it panics if name is already published.
+func NewExpvar(name string) *Expvar {
+	return &Expvar{vars: expvar.NewMap(name), methods: map[string]*methodVars{}}
+}
+
+// Record implements Recorder.
+func (e *Expvar) Record(method string, duration time.Duration, err error) {
+	e.mu.Lock()
+	v, ok := e.methods[method]
+	if !ok {
+		v = &methodVars{}
+		m := new(expvar.Map).Init()
+		m.Set("calls", &v.calls)
+		m.Set("errors", &v.errors)
+		m.Set("latency", &v.latency)
+		e.vars.Set(method, m)
+		e.methods[method] = v
+	}
+	e.mu.Unlock()
+
+	v.calls.Add(1)
+	if err != nil {
+		v.errors.Add(1)
+	}
+	v.latency.Observe(duration)
+}
+
+// bounds are the upper bounds of the buckets of a Histogram.
+var bounds = [...]time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// Histogram is a histogram of durations, with buckets up to 100µs, 1ms,
+// 10ms, 100ms, 1s and 10s, and one for longer durations. It is an
+// expvar.Var, and is safe for concurrent use. Its zero value is an empty
+// histogram.
+type Histogram struct {
+	mu     sync.Mutex
+	counts [len(bounds) + 1]int64 // one per bucket, the last for longer durations
+	count  int64
+	sum    time.Duration
+}
+
+// Observe adds d to h.
+func (h *Histogram) Observe(d time.Duration) {
+	i := 0
+	for i < len(bounds) && d > bounds[i] {
+		i++
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.count++
+	h.sum += d
+}
+
+// String returns h as JSON: the number of durations observed, their sum in
+// seconds, and, for each bucket (e.g., "10ms"), the number of durations at
+// most its bound, as Prometheus histograms do, with "+Inf" for all of them.
+func (h *Histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buckets := make(map[string]int64, len(h.counts))
+	var cumulative int64
+	for i, bound := range bounds {
+		cumulative += h.counts[i]
+		buckets[bound.String()] = cumulative
+	}
+	buckets["+Inf"] = h.count
+	data, _ := json.Marshal(struct {
+		Count   int64            `json:"count"`
+		Sum     float64          `json:"sum"`
+		Buckets map[string]int64 `json:"buckets"`
+	}{h.count, h.sum.Seconds(), buckets})
+	return string(data)
+}
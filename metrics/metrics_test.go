@@ -0,0 +1,115 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ajmesa9891/impl/metrics"
+)
+
+var (
+	_ io.ReadWriter = &MetricsReadWriter{}
+	_ Roarer        = &MetricsRoarer{}
+)
+
+// lion is a Roarer whose roars fail.
+type lion struct{}
+
+func (lion) Roar(context.Context, string, ...int) error { return errTooLoud }
+func (lion) Purr(_ int, loudness float64)               {}
+
+var errTooLoud = errors.New("too loud")
+
+// call is a call recorded by a recorder.
+type call struct {
+	method string
+	err    error
+}
+
+// recorder is a metrics.Recorder that records the calls recorded to it.
+type recorder struct {
+	calls []call
+}
+
+func (r *recorder) Record(method string, duration time.Duration, err error) {
+	r.calls = append(r.calls, call{method, err})
+}
+
+func TestDecorator_RecordsCalls(t *testing.T) {
+	r := &recorder{}
+	m := &MetricsReadWriter{Inner: &bytes.Buffer{}, Recorder: r}
+	m.Write([]byte("roar"))
+	m.Read(make([]byte, 4))
+	m.Read(make([]byte, 4))
+
+	want := []call{{"Write", nil}, {"Read", nil}, {"Read", io.EOF}}
+	if len(r.calls) != len(want) {
+		t.Fatalf("the decorator recorded %v, want %v", r.calls, want)
+	}
+	for i := range want {
+		if r.calls[i] != want[i] {
+			t.Errorf("the decorator recorded %v, want %v", r.calls, want)
+			break
+		}
+	}
+}
+
+func TestDecorator_RecordsMethodsWithoutErrors(t *testing.T) {
+	r := &recorder{}
+	m := &MetricsRoarer{Inner: lion{}, Recorder: r}
+	m.Roar(context.Background(), "roar", 1, 2)
+	m.Purr(1, 0.5)
+
+	want := []call{{"Roar", errTooLoud}, {"Purr", nil}}
+	if len(r.calls) != len(want) || r.calls[0] != want[0] || r.calls[1] != want[1] {
+		t.Errorf("the decorator recorded %v, want %v", r.calls, want)
+	}
+}
+
+func TestExpvar(t *testing.T) {
+	e := metrics.NewExpvar("metrics_test.ReadWriter")
+	m := &MetricsReadWriter{Inner: &bytes.Buffer{}, Recorder: e}
+	m.Write([]byte("roar"))
+	m.Read(make([]byte, 4))
+	m.Read(make([]byte, 4))
+	e.Record("Write", 2*time.Second, nil)
+
+	var got map[string]struct {
+		Calls   int64 `json:"calls"`
+		Errors  int64 `json:"errors"`
+		Latency struct {
+			Count   int64            `json:"count"`
+			Sum     float64          `json:"sum"`
+			Buckets map[string]int64 `json:"buckets"`
+		} `json:"latency"`
+	}
+	published := expvar.Get("metrics_test.ReadWriter").String()
+	if err := json.Unmarshal([]byte(published), &got); err != nil {
+		t.Fatalf("Expvar published %s, which is not JSON: %s", published, err)
+	}
+	read, write := got["Read"], got["Write"]
+	if read.Calls != 2 || read.Errors != 1 || read.Latency.Count != 2 || read.Latency.Buckets["+Inf"] != 2 {
+		t.Errorf("Expvar published %+v for Read, want 2 calls and 1 error", read)
+	}
+	if write.Calls != 2 || write.Errors != 0 || write.Latency.Sum < 2 ||
+		write.Latency.Buckets["1s"] != 1 || write.Latency.Buckets["10s"] != 2 {
+		t.Errorf("Expvar published %+v for Write, want 2 calls, one of them of 2s", write)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	var h metrics.Histogram
+	for _, d := range []time.Duration{50 * time.Microsecond, time.Millisecond, 20 * time.Millisecond, time.Minute} {
+		h.Observe(d)
+	}
+	want := `{"count":4,"sum":60.02105,"buckets":{"+Inf":4,"100ms":3,"100µs":1,"10ms":2,"10s":3,"1ms":2,"1s":3}}`
+	if got := h.String(); got != want {
+		t.Errorf("String() == %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+// Package mock matches the calls made to the mocks written by impl (see
+// impl.Mock) with the calls they expect. Mocks are built on a Mock: their
+// EXPECT builders add the calls it expects, their methods tell it about the
+// calls made to them, and their Finish method reports the expected calls
+// that were not made.
+package mock
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// TestingT is the part of *testing.T that mocks report to.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Mock holds the calls a mock expects and counts the calls made to it. Its
+// zero value expects no calls, and it is safe for concurrent use.
+type Mock struct {
+	mu       sync.Mutex
+	expected []*Call
+}
+
+// Expect adds a call to method with arguments matching args to the calls m
+// expects, and returns it. Arguments that are not Matchers must be equal to
+// the values given (see Eq). The call is expected once, unless told
+// otherwise.
+func (m *Mock) Expect(method string, args ...interface{}) *Call {
+	c := &Call{method: method, min: 1, max: 1}
+	for _, arg := range args {
+		c.args = append(c.args, matcherFor(arg))
+	}
+	if _, file, line, ok := runtime.Caller(2); ok { // the caller of the mock's EXPECT builder
+		c.origin = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expected = append(m.expected, c)
+	return c
+}
+
+// Called tells m about a call to method with the given arguments. It returns
+// the action of the first call m expects that matches it (see
+// Call.SetAction), which is nil if it has none. It panics, describing the
+// calls m expects, if none matches.
+func (m *Mock) Called(method string, args ...interface{}) interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var reasons []string
+	for _, c := range m.expected {
+		if c.method != method {
+			continue
+		}
+		if reason := c.mismatch(args); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s expected at %s %s", c, c.origin, reason))
+			continue
+		}
+		c.calls++
+		return c.action
+	}
+
+	msg := fmt.Sprintf("unexpected call to %s(%s)", method, formatArgs(args))
+	if len(reasons) == 0 {
+		msg += ": no calls to " + method + " are expected"
+	} else {
+		msg += ":\n\t" + strings.Join(reasons, "\n\t")
+	}
+	panic(msg)
+}
+
+// Finish reports to t the calls m expects that were not made as many times
+// as expected.
+func (m *Mock) Finish(t TestingT) {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.expected {
+		if c.calls < c.min {
+			t.Errorf("missing call to %s expected at %s: it was called %d times, want %s", c, c.origin, c.calls, c.times())
+		}
+	}
+}
+
+// Call is a call a Mock expects. Its methods change what is expected, and
+// must be called before the mock is called.
+type Call struct {
+	method   string
+	args     []Matcher
+	min, max int // max is -1 for calls expected any number of times
+	calls    int
+	after    []*Call
+	action   interface{}
+	origin   string // the position it was expected at
+}
+
+// Times expects the call n times.
+func (c *Call) Times(n int) *Call {
+	c.min, c.max = n, n
+	return c
+}
+
+// AnyTimes expects the call any number of times, including none.
+func (c *Call) AnyTimes() *Call {
+	c.min, c.max = 0, -1
+	return c
+}
+
+// After expects the call only after the calls prev were made as many times
+// as they are expected.
+func (c *Call) After(prev ...Expectation) *Call {
+	for _, p := range prev {
+		c.after = append(c.after, p.expectation())
+	}
+	return c
+}
+
+// SetAction sets what the mock does when the call is made. The action is a
+// func with the signature of the mocked method, which mocks call with the
+// call's arguments and whose results they return. It is meant to be set
+// through the typed Return, DoAndReturn and Do methods of mocks.
+func (c *Call) SetAction(action interface{}) *Call {
+	c.action = action
+	return c
+}
+
+func (c *Call) expectation() *Call {
+	return c
+}
+
+func (c *Call) String() string {
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("%s(%s)", c.method, strings.Join(args, ", "))
+}
+
+// times describes how many times the call is expected (e.g., "2" or "any
+// number of times").
+func (c *Call) times() string {
+	if c.max < 0 {
+		if c.min == 0 {
+			return "any number of times"
+		}
+		return fmt.Sprintf("at least %d", c.min)
+	}
+	return fmt.Sprintf("%d", c.max)
+}
+
+// mismatch describes why the call does not match a call with the given
+// arguments, or returns "" if it does.
+func (c *Call) mismatch(args []interface{}) string {
+	if len(args) != len(c.args) {
+		return fmt.Sprintf("has %d arguments, not %d", len(c.args), len(args))
+	}
+	for i, arg := range args {
+		if !c.args[i].Matches(arg) {
+			return fmt.Sprintf("wants argument %d to %s, not %s", i+1, c.args[i], formatArgs([]interface{}{arg}))
+		}
+	}
+	if c.max >= 0 && c.calls >= c.max {
+		return fmt.Sprintf("was already called %d times", c.calls)
+	}
+	for _, prev := range c.after {
+		if prev.calls < prev.min {
+			return fmt.Sprintf("must be called after %s", prev)
+		}
+	}
+	return ""
+}
+
+// Expectation is a call a Mock expects: a *Call, or a mock's typed wrapper
+// around one.
+type Expectation interface {
+	expectation() *Call
+}
+
+// InOrder expects the calls only in the given order: each call after the
+// one before it.
+func InOrder(calls ...Expectation) {
+	for i := 1; i < len(calls); i++ {
+		calls[i].expectation().After(calls[i-1])
+	}
+}
+
+func formatArgs(args []interface{}) string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		s[i] = fmt.Sprintf("%#v", arg)
+	}
+	return strings.Join(s, ", ")
+}
+
+// Matcher matches an argument of a call.
+type Matcher interface {
+	// Matches reports whether x matches.
+	Matches(x interface{}) bool
+
+	// String describes the arguments that match (e.g., "is anything").
+	String() string
+}
+
+// matcherFor returns arg if it is a Matcher, or a Matcher of the values
+// equal to it otherwise.
+func matcherFor(arg interface{}) Matcher {
+	if m, ok := arg.(Matcher); ok {
+		return m
+	}
+	return Eq(arg)
+}
+
+type condMatcher struct {
+	description string
+	cond        func(x interface{}) bool
+}
+
+func (m condMatcher) Matches(x interface{}) bool {
+	return m.cond(x)
+}
+
+func (m condMatcher) String() string {
+	return m.description
+}
+
+// Cond returns a Matcher of the values cond holds for, described by
+// description (e.g., "is positive").
+func Cond(description string, cond func(x interface{}) bool) Matcher {
+	return condMatcher{description, cond}
+}
+
+// Any returns a Matcher of any value.
+func Any() Matcher {
+	return Cond("is anything", func(interface{}) bool { return true })
+}
+
+// Eq returns a Matcher of the values deeply equal to want (see
+// reflect.DeepEqual). A nil want matches nil values of any type.
+func Eq(want interface{}) Matcher {
+	if want == nil {
+		return Nil()
+	}
+	return Cond(fmt.Sprintf("is equal to %#v", want), func(x interface{}) bool {
+		return reflect.DeepEqual(x, want)
+	})
+}
+
+// Nil returns a Matcher of nil values of any type (e.g., a nil error or a
+// nil []byte).
+func Nil() Matcher {
+	return Cond("is nil", func(x interface{}) bool {
+		if x == nil {
+			return true
+		}
+		switch v := reflect.ValueOf(x); v.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return v.IsNil()
+		}
+		return false
+	})
+}
+
+// Not returns a Matcher of the values m does not match. Values that are not
+// Matchers are matched as by Eq.
+func Not(m interface{}) Matcher {
+	matcher := matcherFor(m)
+	return Cond("not "+matcher.String(), func(x interface{}) bool {
+		return !matcher.Matches(x)
+	})
+}
@@ -0,0 +1,209 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ajmesa9891/impl/mock"
+)
+
+var (
+	_ io.ReadWriter = &MockReadWriter{}
+	_ Roarer        = &MockRoarer{}
+)
+
+// recorder is a mock.TestingT that records what is reported to it.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// panicOf returns what f panics with, or "" if it does not panic.
+func panicOf(f func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	f()
+	return ""
+}
+
+func TestMock_ReturnsWhatIsExpected(t *testing.T) {
+	m := &MockReadWriter{}
+	m.EXPECT().Read(mock.Any()).Return(3, nil)
+	m.EXPECT().Write([]byte("roar")).Return(0, io.ErrShortWrite)
+	m.EXPECT().Write(mock.Not([]byte("roar"))).DoAndReturn(func(p []byte) (int, error) {
+		return len(p), nil
+	})
+
+	if n, err := m.Read(make([]byte, 8)); n != 3 || err != nil {
+		t.Errorf("Read(...) == %d, %v, want 3, nil", n, err)
+	}
+	if n, err := m.Write([]byte("purr")); n != 4 || err != nil {
+		t.Errorf("Write(%q) == %d, %v, want 4, nil", "purr", n, err)
+	}
+	if n, err := m.Write([]byte("roar")); n != 0 || err != io.ErrShortWrite {
+		t.Errorf("Write(%q) == %d, %v, want 0, %v", "roar", n, err, io.ErrShortWrite)
+	}
+	m.Finish(t)
+}
+
+func TestMock_ReturnsZeroValuesWithoutAnAction(t *testing.T) {
+	m := &MockReadWriter{}
+	m.EXPECT().Read(nil)
+	if n, err := m.Read(nil); n != 0 || err != nil {
+		t.Errorf("Read(nil) == %d, %v, want 0, nil", n, err)
+	}
+	m.Finish(t)
+}
+
+func TestMock_MatchesVariadicArgumentsAsASlice(t *testing.T) {
+	ctx := context.Background()
+	m := &MockRoarer{}
+	roared := errors.New("roared")
+	m.EXPECT().Roar(ctx, "loud", []int{1, 2}).Return(roared)
+	m.EXPECT().Roar(ctx, mock.Any(), mock.Nil()).Return(nil)
+	purrs := 0
+	m.EXPECT().Purr(mock.Cond("is positive", func(x interface{}) bool { return x.(int) > 0 }), 0.5).
+		Do(func(int, float64) { purrs++ }).
+		Times(2)
+
+	if err := m.Roar(ctx, "soft"); err != nil {
+		t.Errorf("Roar(ctx, %q) == %v, want nil", "soft", err)
+	}
+	if err := m.Roar(ctx, "loud", 1, 2); err != roared {
+		t.Errorf("Roar(ctx, %q, 1, 2) == %v, want %v", "loud", err, roared)
+	}
+	m.Purr(1, 0.5)
+	m.Purr(2, 0.5)
+	if purrs != 2 {
+		t.Errorf("Purr(...) was done %d times, want 2", purrs)
+	}
+	m.Finish(t)
+}
+
+func TestMock_CountsCalls(t *testing.T) {
+	m := &MockReadWriter{}
+	m.EXPECT().Read(mock.Any()).Return(1, nil).Times(2)
+	m.EXPECT().Read(mock.Any()).Return(0, io.EOF).AnyTimes()
+
+	for i, want := range []int{1, 1, 0, 0} {
+		if n, _ := m.Read(nil); n != want {
+			t.Errorf("Read(nil) #%d == %d, want %d", i+1, n, want)
+		}
+	}
+	m.Finish(t)
+}
+
+func TestMock_FinishReportsMissingCalls(t *testing.T) {
+	m := &MockReadWriter{}
+	m.EXPECT().Read(mock.Any()).Times(2)
+	m.EXPECT().Write(mock.Any()).AnyTimes()
+	m.Read(nil)
+
+	r := &recorder{}
+	m.Finish(r)
+	if len(r.errors) != 1 {
+		t.Fatalf("Finish(...) reported %q, want 1 error", r.errors)
+	}
+	want := "missing call to Read(is anything) expected at mock_test.go:"
+	if !strings.HasPrefix(r.errors[0], want) || !strings.HasSuffix(r.errors[0], "it was called 1 times, want 2") {
+		t.Errorf("Finish(...) reported %q, want %q...", r.errors[0], want)
+	}
+}
+
+func TestMock_PanicsOnUnexpectedCalls(t *testing.T) {
+	cases := []struct {
+		name   string
+		expect func(m *MockReadWriter)
+		want   string
+	}{
+		{
+			"not expected",
+			func(m *MockReadWriter) {},
+			"unexpected call to Write([]byte{0x61}): no calls to Write are expected",
+		},
+		{
+			"different arguments",
+			func(m *MockReadWriter) { m.EXPECT().Write([]byte("b")) },
+			"unexpected call to Write([]byte{0x61}):\n\tWrite(is equal to []byte{0x62}) expected at",
+		},
+		{
+			"called too many times",
+			func(m *MockReadWriter) {
+				m.EXPECT().Write(mock.Any())
+				m.Write(nil)
+			},
+			"was already called 1 times",
+		},
+	}
+	for _, c := range cases {
+		m := &MockReadWriter{}
+		c.expect(m)
+		got := panicOf(func() { m.Write([]byte("a")) })
+		if !strings.Contains(got, c.want) {
+			t.Errorf("%s: Write(...) panicked with %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestInOrder(t *testing.T) {
+	m := &MockReadWriter{}
+	mock.InOrder(
+		m.EXPECT().Write([]byte("first")),
+		m.EXPECT().Write(mock.Any()).Times(2),
+		m.EXPECT().Read(mock.Any()),
+	)
+
+	got := panicOf(func() { m.Read(nil) })
+	if want := "must be called after Write(is anything)"; !strings.Contains(got, want) {
+		t.Errorf("Read(nil) before the writes panicked with %q, want %q", got, want)
+	}
+	m.Write([]byte("first"))
+	m.Write([]byte("second"))
+	got = panicOf(func() { m.Read(nil) })
+	if want := "must be called after Write(is anything)"; !strings.Contains(got, want) {
+		t.Errorf("Read(nil) after 2 writes panicked with %q, want %q", got, want)
+	}
+	m.Write([]byte("third"))
+	m.Read(nil)
+	m.Finish(t)
+}
+
+func TestMatchers(t *testing.T) {
+	var nilErr error
+	var nilSlice []byte
+	cases := []struct {
+		matcher mock.Matcher
+		x       interface{}
+		want    bool
+	}{
+		{mock.Any(), nil, true},
+		{mock.Any(), 1, true},
+		{mock.Eq(1), 1, true},
+		{mock.Eq(1), int64(1), false},
+		{mock.Eq([]int{1}), []int{1}, true},
+		{mock.Eq(nil), nilSlice, true},
+		{mock.Nil(), nilErr, true},
+		{mock.Nil(), nilSlice, true},
+		{mock.Nil(), 0, false},
+		{mock.Not(1), 2, true},
+		{mock.Not(mock.Nil()), nilSlice, false},
+		{mock.Cond("is even", func(x interface{}) bool { return x.(int)%2 == 0 }), 4, true},
+	}
+	for _, c := range cases {
+		if got := c.matcher.Matches(c.x); got != c.want {
+			t.Errorf("%s: Matches(%#v) == %t, want %t", c.matcher, c.x, got, c.want)
+		}
+	}
+}
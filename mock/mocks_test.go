@@ -0,0 +1,172 @@
+package mock_test
+
+import (
+	"context"
+
+	"github.com/ajmesa9891/impl/mock"
+)
+
+type Roarer interface {
+	Roar(context.Context, string, ...int) error
+	Purr(_ int, loudness float64)
+}
+
+// MockReadWriter and MockRoarer are written by goimpl -mode=mock. Package
+// impl's TestImplWithOptions_WrittenTestFiles checks that they still are.
+
+// MockReadWriter is a mock io.ReadWriter. Set the calls it expects with EXPECT,
+// and check that they were all made with Finish.
+type MockReadWriter struct {
+	mock mock.Mock
+}
+
+// EXPECT returns the builder of the calls m expects.
+func (m *MockReadWriter) EXPECT() MockReadWriterExpect {
+	return MockReadWriterExpect{&m.mock}
+}
+
+// Finish reports to t the calls m expects that were not made.
+func (m *MockReadWriter) Finish(t mock.TestingT) {
+	t.Helper()
+	m.mock.Finish(t)
+}
+
+func (m *MockReadWriter) Read(p []byte) (n int, err error) {
+	if do, ok := m.mock.Called("Read", p).(func([]byte) (int, error)); ok {
+		return do(p)
+	}
+	return
+}
+
+func (m *MockReadWriter) Write(p []byte) (n int, err error) {
+	if do, ok := m.mock.Called("Write", p).(func([]byte) (int, error)); ok {
+		return do(p)
+	}
+	return
+}
+
+// MockReadWriterExpect builds the calls a MockReadWriter expects.
+type MockReadWriterExpect struct {
+	mock *mock.Mock
+}
+
+// Read expects a call to Read with arguments matching the given
+// mock.Matchers, or equal to the given values.
+func (e MockReadWriterExpect) Read(p interface{}) MockReadWriterReadCall {
+	return MockReadWriterReadCall{e.mock.Expect("Read", p)}
+}
+
+// MockReadWriterReadCall is a call to Read a MockReadWriter expects.
+type MockReadWriterReadCall struct {
+	*mock.Call
+}
+
+// Return makes the call return n, err.
+func (c MockReadWriterReadCall) Return(n int, err error) MockReadWriterReadCall {
+	c.SetAction(func([]byte) (int, error) { return n, err })
+	return c
+}
+
+// DoAndReturn makes the call return what do returns, called with its arguments.
+func (c MockReadWriterReadCall) DoAndReturn(do func([]byte) (int, error)) MockReadWriterReadCall {
+	c.SetAction(do)
+	return c
+}
+
+// Write expects a call to Write with arguments matching the given
+// mock.Matchers, or equal to the given values.
+func (e MockReadWriterExpect) Write(p interface{}) MockReadWriterWriteCall {
+	return MockReadWriterWriteCall{e.mock.Expect("Write", p)}
+}
+
+// MockReadWriterWriteCall is a call to Write a MockReadWriter expects.
+type MockReadWriterWriteCall struct {
+	*mock.Call
+}
+
+// Return makes the call return n, err.
+func (c MockReadWriterWriteCall) Return(n int, err error) MockReadWriterWriteCall {
+	c.SetAction(func([]byte) (int, error) { return n, err })
+	return c
+}
+
+// DoAndReturn makes the call return what do returns, called with its arguments.
+func (c MockReadWriterWriteCall) DoAndReturn(do func([]byte) (int, error)) MockReadWriterWriteCall {
+	c.SetAction(do)
+	return c
+}
+
+// MockRoarer is a mock Roarer. Set the calls it expects with EXPECT,
+// and check that they were all made with Finish.
+type MockRoarer struct {
+	mock mock.Mock
+}
+
+// EXPECT returns the builder of the calls m expects.
+func (m *MockRoarer) EXPECT() MockRoarerExpect {
+	return MockRoarerExpect{&m.mock}
+}
+
+// Finish reports to t the calls m expects that were not made.
+func (m *MockRoarer) Finish(t mock.TestingT) {
+	t.Helper()
+	m.mock.Finish(t)
+}
+
+func (m *MockRoarer) Roar(p0 context.Context, p1 string, p2 ...int) (r0 error) {
+	if do, ok := m.mock.Called("Roar", p0, p1, p2).(func(context.Context, string, ...int) error); ok {
+		return do(p0, p1, p2...)
+	}
+	return
+}
+
+func (m *MockRoarer) Purr(p0 int, loudness float64) {
+	if do, ok := m.mock.Called("Purr", p0, loudness).(func(int, float64)); ok {
+		do(p0, loudness)
+	}
+}
+
+// MockRoarerExpect builds the calls a MockRoarer expects.
+type MockRoarerExpect struct {
+	mock *mock.Mock
+}
+
+// Roar expects a call to Roar with arguments matching the given
+// mock.Matchers, or equal to the given values.
+func (e MockRoarerExpect) Roar(p0 interface{}, p1 interface{}, p2 interface{}) MockRoarerRoarCall {
+	return MockRoarerRoarCall{e.mock.Expect("Roar", p0, p1, p2)}
+}
+
+// MockRoarerRoarCall is a call to Roar a MockRoarer expects.
+type MockRoarerRoarCall struct {
+	*mock.Call
+}
+
+// Return makes the call return r0.
+func (c MockRoarerRoarCall) Return(r0 error) MockRoarerRoarCall {
+	c.SetAction(func(context.Context, string, ...int) error { return r0 })
+	return c
+}
+
+// DoAndReturn makes the call return what do returns, called with its arguments.
+func (c MockRoarerRoarCall) DoAndReturn(do func(context.Context, string, ...int) error) MockRoarerRoarCall {
+	c.SetAction(do)
+	return c
+}
+
+// Purr expects a call to Purr with arguments matching the given
+// mock.Matchers, or equal to the given values.
+func (e MockRoarerExpect) Purr(p0 interface{}, loudness interface{}) MockRoarerPurrCall {
+	return MockRoarerPurrCall{e.mock.Expect("Purr", p0, loudness)}
+}
+
+// MockRoarerPurrCall is a call to Purr a MockRoarer expects.
+type MockRoarerPurrCall struct {
+	*mock.Call
+}
+
+// Do makes the call call do with its arguments.
+func (c MockRoarerPurrCall) Do(do func(int, float64)) MockRoarerPurrCall {
+	c.SetAction(do)
+	return c
+}
@@ -0,0 +1,64 @@
+package replay_test
+
+import (
+	"context"
+	"io"
+
+	"github.com/ajmesa9891/impl/replay"
+)
+
+type Roarer interface {
+	Roar(context.Context, string, ...int) error
+	Purr(_ int, loudness float64)
+}
+
+// RecordReadWriter and RecordRoarer are as goimpl -mode=recorder writes them
+// (see TestImplWithOptions_WrittenTestFiles in package impl).
+
+// RecordReadWriter records the calls made to it to Tape, passing them on to Inner,
+// or answers them from Tape, depending on whether Tape records.
+type RecordReadWriter struct {
+	Inner io.ReadWriter
+	Tape  *replay.Tape
+}
+
+func (r *RecordReadWriter) Read(p []byte) (n int, err error) {
+	call := r.Tape.Call("Read", &p)
+	if call.Recording() {
+		n, err = r.Inner.Read(p)
+	}
+	call.Return(&n, &err)
+	return
+}
+
+func (r *RecordReadWriter) Write(p []byte) (n int, err error) {
+	call := r.Tape.Call("Write", &p)
+	if call.Recording() {
+		n, err = r.Inner.Write(p)
+	}
+	call.Return(&n, &err)
+	return
+}
+
+// RecordRoarer records the calls made to it to Tape, passing them on to Inner,
+// or answers them from Tape, depending on whether Tape records.
+type RecordRoarer struct {
+	Inner Roarer
+	Tape  *replay.Tape
+}
+
+func (r *RecordRoarer) Roar(p0 context.Context, p1 string, p2 ...int) (r0 error) {
+	call := r.Tape.Call("Roar", &p1, &p2)
+	if call.Recording() {
+		r0 = r.Inner.Roar(p0, p1, p2...)
+	}
+	call.Return(&r0)
+	return
+}
+
+func (r *RecordRoarer) Purr(p0 int, loudness float64) {
+	call := r.Tape.Call("Purr", &p0, &loudness)
+	if call.Recording() {
+		r.Inner.Purr(p0, loudness)
+	}
+}
@@ -0,0 +1,261 @@
+// Package replay records the calls made to the recorders written by impl
+// (see impl.Recorder) to golden files, and replays them from those files.
+// Recorders are built on a Tape: when it records, their methods pass the
+// calls made to them on to the implementation they wrap and record their
+// arguments and results, and when it replays, they check that the calls
+// made to them are the calls recorded and answer them with the results
+// recorded.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TestingT is the part of *testing.T that tapes report to.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// recordedCall is a call as it is written to a golden file.
+type recordedCall struct {
+	Method  string            `json:"method"`
+	Args    []json.RawMessage `json:"args"`
+	Results []json.RawMessage `json:"results,omitempty"`
+}
+
+// Tape holds the calls recorded to, or replayed from, a golden file. It is
+// safe for concurrent use, but calls are replayed in the order they were
+// recorded.
+type Tape struct {
+	mu        sync.Mutex
+	path      string
+	recording bool
+	calls     []*recordedCall
+	next      int // the index of the next call to replay
+	err       error
+}
+
+// Open returns a tape for the golden file at path. When record is true, the
+// tape records the calls made, and Finish writes them to path. Otherwise,
+// the calls recorded at path are read to be replayed.
+func Open(path string, record bool) (*Tape, error) {
+	t := &Tape{path: path, recording: record}
+	if record {
+		return t, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: reading golden file: %s (record it first)", err)
+	}
+	if err := json.Unmarshal(data, &t.calls); err != nil {
+		return nil, fmt.Errorf("replay: reading golden file %s: %s", path, err)
+	}
+	for _, c := range t.calls { // indented by Finish
+		for _, values := range [][]json.RawMessage{c.Args, c.Results} {
+			for i, v := range values {
+				var compact bytes.Buffer
+				if err := json.Compact(&compact, v); err != nil {
+					return nil, fmt.Errorf("replay: reading golden file %s: %s", path, err)
+				}
+				values[i] = compact.Bytes()
+			}
+		}
+	}
+	return t, nil
+}
+
+// Recording reports whether t records calls, rather than replaying them.
+func (t *Tape) Recording() bool {
+	return t.recording
+}
+
+// Call starts a call to method with the given arguments, which are pointers
+// to them. When t records, the arguments are recorded. When it replays, they
+// are checked against the next call recorded, and Call panics, describing
+// both calls, if they differ. Arguments of type error are recorded as their
+// message.
+func (t *Tape) Call(method string, args ...interface{}) *Call {
+	encoded, err := encodeValues(args)
+	if err != nil {
+		panic(fmt.Sprintf("replay: call to %s: %s", method, err))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.recording {
+		c := &recordedCall{Method: method, Args: encoded}
+		t.calls = append(t.calls, c)
+		return &Call{tape: t, recorded: c}
+	}
+
+	made := &recordedCall{Method: method, Args: encoded}
+	if t.next >= len(t.calls) {
+		panic(fmt.Sprintf("replay: call #%d %s diverges from %s: only %d calls were recorded",
+			t.next+1, made, t.path, len(t.calls)))
+	}
+	c := t.calls[t.next]
+	if c.String() != made.String() {
+		panic(fmt.Sprintf("replay: call #%d %s diverges from %s, which recorded %s",
+			t.next+1, made, t.path, c))
+	}
+	t.next++
+	return &Call{tape: t, recorded: c}
+}
+
+// Finish reports to tt what went wrong with t. When t records, Finish writes
+// the calls recorded to its golden file, creating its directory if needed.
+// When it replays, Finish reports the calls recorded that were not made.
+func (t *Tape) Finish(tt TestingT) {
+	tt.Helper()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		tt.Errorf("%s", t.err)
+	}
+	if !t.recording {
+		if t.next < len(t.calls) {
+			tt.Errorf("replay: %d of the calls recorded in %s were not made, starting with call #%d %s",
+				len(t.calls)-t.next, t.path, t.next+1, t.calls[t.next])
+		}
+		return
+	}
+
+	data, err := json.MarshalIndent(t.calls, "", "  ")
+	if err != nil {
+		tt.Errorf("replay: writing golden file %s: %s", t.path, err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		tt.Errorf("replay: writing golden file: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(t.path, append(data, '\n'), 0644); err != nil {
+		tt.Errorf("replay: writing golden file: %s", err)
+	}
+}
+
+func (c *recordedCall) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = string(arg)
+	}
+	return fmt.Sprintf("%s(%s)", c.Method, strings.Join(args, ", "))
+}
+
+// Call is a call started on a Tape.
+type Call struct {
+	tape     *Tape
+	recorded *recordedCall
+}
+
+// Recording reports whether the call is recorded, so that it has to be
+// passed on to the implementation recorded, rather than replayed.
+func (c *Call) Recording() bool {
+	return c.tape.recording
+}
+
+// Return finishes the call with the given results, which are pointers to
+// them. When the call is recorded, the results are recorded. When it is
+// replayed, the results recorded are stored in them. Results of type error
+// are recorded as their message, and replayed as errors with that message:
+// the errors registered with RegisterErrors are replayed as themselves.
+func (c *Call) Return(results ...interface{}) {
+	if c.Recording() {
+		encoded, err := encodeValues(results)
+		c.tape.mu.Lock()
+		defer c.tape.mu.Unlock()
+		if err != nil && c.tape.err == nil {
+			c.tape.err = fmt.Errorf("replay: results of %s: %s", c.recorded, err)
+		}
+		c.recorded.Results = encoded
+		return
+	}
+
+	if len(c.recorded.Results) != len(results) {
+		panic(fmt.Sprintf("replay: %s recorded %d results, not %d", c.recorded, len(c.recorded.Results), len(results)))
+	}
+	for i, result := range results {
+		if err := decodeValue(c.recorded.Results[i], result); err != nil {
+			panic(fmt.Sprintf("replay: result %d of %s: %s", i+1, c.recorded, err))
+		}
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// sentinels are the errors replayed as themselves, by message.
+var (
+	sentinelsMu sync.Mutex
+	sentinels   = map[string]error{}
+)
+
+// RegisterErrors makes errs, when recorded, be replayed as themselves rather
+// than as new errors with their message, so that they can be compared with
+// ==. io.EOF, io.ErrUnexpectedEOF, context.Canceled and
+// context.DeadlineExceeded are registered.
+func RegisterErrors(errs ...error) {
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+	for _, err := range errs {
+		sentinels[err.Error()] = err
+	}
+}
+
+func init() {
+	RegisterErrors(io.EOF, io.ErrUnexpectedEOF, context.Canceled, context.DeadlineExceeded)
+}
+
+// encodeValues encodes the values pointed to by ptrs as JSON. Values of
+// type error are encoded as their message, or null.
+func encodeValues(ptrs []interface{}) ([]json.RawMessage, error) {
+	encoded := make([]json.RawMessage, len(ptrs))
+	for i, ptr := range ptrs {
+		v := reflect.ValueOf(ptr).Elem()
+		var x interface{} = v.Interface()
+		if v.Type() == errorType && !v.IsNil() {
+			x = v.Interface().(error).Error()
+		}
+		data, err := json.Marshal(x)
+		if err != nil {
+			return nil, fmt.Errorf("value #%d of type %s cannot be recorded: %s", i+1, v.Type(), err)
+		}
+		encoded[i] = data
+	}
+	return encoded, nil
+}
+
+// decodeValue decodes data into the value pointed to by ptr. Values of type
+// error are decoded from their message.
+func decodeValue(data json.RawMessage, ptr interface{}) error {
+	v := reflect.ValueOf(ptr).Elem()
+	if v.Type() != errorType {
+		return json.Unmarshal(data, ptr)
+	}
+	var msg *string
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	if msg == nil {
+		v.Set(reflect.Zero(errorType))
+		return nil
+	}
+	sentinelsMu.Lock()
+	err, ok := sentinels[*msg]
+	sentinelsMu.Unlock()
+	if !ok {
+		err = errors.New(*msg)
+	}
+	v.Set(reflect.ValueOf(&err).Elem())
+	return nil
+}
@@ -0,0 +1,225 @@
+package replay_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ajmesa9891/impl/replay"
+)
+
+var (
+	_ io.ReadWriter = &RecordReadWriter{}
+	_ Roarer        = &RecordRoarer{}
+)
+
+// reporter is a replay.TestingT that records what is reported to it.
+type reporter struct {
+	errors []string
+}
+
+func (r *reporter) Helper() {}
+
+func (r *reporter) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// panicOf returns what f panics with, or "" if it does not panic.
+func panicOf(f func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	f()
+	return ""
+}
+
+// lion is the Roarer recorded, which counts the calls made to it.
+type lion struct {
+	calls int
+}
+
+func (l *lion) Roar(ctx context.Context, sound string, times ...int) error {
+	l.calls++
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(times) > 1 {
+		return errors.New("too loud")
+	}
+	return nil
+}
+
+func (l *lion) Purr(_ int, loudness float64) {
+	l.calls++
+}
+
+// record records the calls made by roar to a Roarer at path.
+func record(t *testing.T, path string, roar func(Roarer)) {
+	tape, err := replay.Open(path, true)
+	if err != nil {
+		t.Fatalf("Open(%q, true) failed: %s", path, err)
+	}
+	l := &lion{}
+	roar(&RecordRoarer{Inner: l, Tape: tape})
+	tape.Finish(t)
+	if l.calls == 0 {
+		t.Fatalf("recording called the recorded Roarer %d times", l.calls)
+	}
+}
+
+// replayed returns a RecordRoarer replaying the calls recorded at path.
+func replayed(t *testing.T, path string) (*RecordRoarer, *replay.Tape) {
+	tape, err := replay.Open(path, false)
+	if err != nil {
+		t.Fatalf("Open(%q, false) failed: %s", path, err)
+	}
+	return &RecordRoarer{Tape: tape}, tape
+}
+
+func TestTape_ReplaysWhatWasRecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "roarer.json")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	roar := func(r Roarer) {
+		r.Roar(context.Background(), "roar")
+		r.Roar(context.Background(), "ROAR", 1, 2)
+		r.Roar(canceled, "roar", 3)
+		r.Purr(1, 0.5)
+	}
+	record(t, path, roar)
+
+	r, tape := replayed(t, path)
+	if err := r.Roar(context.Background(), "roar"); err != nil {
+		t.Errorf("Roar(ctx, %q) == %v, want nil", "roar", err)
+	}
+	if err := r.Roar(context.Background(), "ROAR", 1, 2); err == nil || err.Error() != "too loud" {
+		t.Errorf("Roar(ctx, %q, 1, 2) == %v, want too loud", "ROAR", err)
+	}
+	if err := r.Roar(context.Background(), "roar", 3); err != context.Canceled {
+		t.Errorf("Roar(ctx, %q, 3) == %v, want %v itself", "roar", err, context.Canceled)
+	}
+	r.Purr(1, 0.5)
+	tape.Finish(t)
+}
+
+func TestTape_ReplaysReadWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readwriter.json")
+	tape, err := replay.Open(path, true)
+	if err != nil {
+		t.Fatalf("Open(%q, true) failed: %s", path, err)
+	}
+	rw := &RecordReadWriter{Inner: &bytes.Buffer{}, Tape: tape}
+	rw.Write([]byte("purr"))
+	rw.Read(make([]byte, 4))
+	rw.Read(make([]byte, 4))
+	tape.Finish(t)
+
+	tape, err = replay.Open(path, false)
+	if err != nil {
+		t.Fatalf("Open(%q, false) failed: %s", path, err)
+	}
+	rw = &RecordReadWriter{Tape: tape}
+	if n, err := rw.Write([]byte("purr")); n != 4 || err != nil {
+		t.Errorf("Write(%q) == %d, %v, want 4, nil", "purr", n, err)
+	}
+	if n, err := rw.Read(make([]byte, 4)); n != 4 || err != nil {
+		t.Errorf("Read(...) == %d, %v, want 4, nil", n, err)
+	}
+	if n, err := rw.Read(make([]byte, 4)); n != 0 || err != io.EOF {
+		t.Errorf("Read(...) == %d, %v, want 0, %v", n, err, io.EOF)
+	}
+	tape.Finish(t)
+}
+
+func TestTape_PanicsOnDivergence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roarer.json")
+	record(t, path, func(r Roarer) {
+		r.Roar(context.Background(), "roar", 1)
+	})
+
+	cases := []struct {
+		name string
+		roar func(Roarer)
+		want string
+	}{
+		{
+			"other arguments",
+			func(r Roarer) { r.Roar(context.Background(), "roar", 2) },
+			`call #1 Roar("roar", [2]) diverges from ` + path + `, which recorded Roar("roar", [1])`,
+		},
+		{
+			"other method",
+			func(r Roarer) { r.Purr(1, 0.5) },
+			`call #1 Purr(1, 0.5) diverges from ` + path + `, which recorded Roar("roar", [1])`,
+		},
+		{
+			"more calls",
+			func(r Roarer) {
+				r.Roar(context.Background(), "roar", 1)
+				r.Roar(context.Background(), "roar", 1)
+			},
+			`call #2 Roar("roar", [1]) diverges from ` + path + `: only 1 calls were recorded`,
+		},
+	}
+	for _, c := range cases {
+		r, _ := replayed(t, path)
+		if got := panicOf(func() { c.roar(r) }); !strings.Contains(got, c.want) {
+			t.Errorf("%s: replaying panicked with %q, want it to contain %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestTape_FinishReportsCallsNotMade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roarer.json")
+	record(t, path, func(r Roarer) {
+		r.Roar(context.Background(), "roar")
+		r.Purr(1, 0.5)
+	})
+
+	r, tape := replayed(t, path)
+	r.Roar(context.Background(), "roar")
+	rep := &reporter{}
+	tape.Finish(rep)
+	if len(rep.errors) != 1 || !strings.Contains(rep.errors[0], "1 of the calls recorded") || !strings.Contains(rep.errors[0], "call #2 Purr(1, 0.5)") {
+		t.Errorf("Finish(...) reported %q, want the call to Purr not made", rep.errors)
+	}
+}
+
+func TestOpen_FailsWithoutGoldenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+	if _, err := replay.Open(path, false); err == nil || !strings.Contains(err.Error(), "record it first") {
+		t.Errorf("Open(%q, false) == %v, want an error telling to record it first", path, err)
+	}
+}
+
+var errTooQuiet = errors.New("too quiet")
+
+// quietRoarer is a Roarer that fails with errTooQuiet.
+type quietRoarer struct{}
+
+func (quietRoarer) Roar(context.Context, string, ...int) error { return errTooQuiet }
+func (quietRoarer) Purr(_ int, loudness float64)               {}
+
+func TestRegisterErrors(t *testing.T) {
+	replay.RegisterErrors(errTooQuiet)
+	path := filepath.Join(t.TempDir(), "roarer.json")
+	tape, err := replay.Open(path, true)
+	if err != nil {
+		t.Fatalf("Open(%q, true) failed: %s", path, err)
+	}
+	(&RecordRoarer{Inner: quietRoarer{}, Tape: tape}).Roar(context.Background(), "purr")
+	tape.Finish(t)
+
+	r, tape := replayed(t, path)
+	if err := r.Roar(context.Background(), "purr"); err != errTooQuiet {
+		t.Errorf("Roar(ctx, %q) == %v, want %v itself", "purr", err, errTooQuiet)
+	}
+	tape.Finish(t)
+}
@@ -0,0 +1,53 @@
+package retry_test
+
+import (
+	"context"
+
+	"github.com/ajmesa9891/impl/retry"
+)
+
+type Page struct {
+	Body string
+}
+
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (Page, int, error)
+	Ping() error
+	Len() int
+}
+
+// RetryFetcher is generated by goimpl -mode=retry for Fetcher; package impl's
+// tests check that it matches what goimpl writes.
+
+// RetryFetcher passes the calls made to it on to Inner, and attempts again the
+// calls to its methods returning an error that fail, as Policy tells.
+type RetryFetcher struct {
+	Inner  Fetcher
+	Policy retry.Policy
+}
+
+func (r *RetryFetcher) Fetch(ctx context.Context, url string) (r0 Page, r1 int, r2 error) {
+	for attempt := 1; ; attempt++ {
+		if r0, r1, r2 = r.Inner.Fetch(ctx, url); r2 == nil {
+			return
+		}
+		if !r.Policy.Retry(ctx, attempt, r2) {
+			return Page{}, 0, r2
+		}
+	}
+}
+
+func (r *RetryFetcher) Ping() (r0 error) {
+	for attempt := 1; ; attempt++ {
+		if r0 = r.Inner.Ping(); r0 == nil {
+			return
+		}
+		if !r.Policy.Retry(context.Background(), attempt, r0) {
+			return r0
+		}
+	}
+}
+
+func (r *RetryFetcher) Len() (r0 int) {
+	return r.Inner.Len()
+}
@@ -0,0 +1,72 @@
+// Package retry holds the policies of the retry decorators written by impl
+// (see impl.Retry), which attempt again the calls made to them that fail as
+// their Policy tells.
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Policy tells when, and after how long, the calls that fail are attempted
+// again. Its zero value attempts calls once.
+type Policy struct {
+	// MaxAttempts is the number of times a call is attempted, the first one
+	// included. Calls are attempted once when it is less than 2.
+	MaxAttempts int
+
+	// Backoff, unless it is nil, returns how long to wait after the given
+	// attempt (1 for the first one) failed before attempting the call again
+	// (see Exponential). Calls are attempted again right away when it is
+	// nil.
+	Backoff func(attempt int) time.Duration
+
+	// Retryable, unless it is nil, reports whether a call that failed with
+	// err can be attempted again (e.g., because err is temporary). Calls that
+	// fail with any error are attempted again when it is nil.
+	Retryable func(err error) bool
+}
+
+// Retry reports whether a call that failed with err at the given attempt (1
+// for the first one) is attempted again, after waiting as p tells. It
+// returns false without waiting when err is nil, the call was attempted
+// p.MaxAttempts times, or err cannot be retried, and as soon as ctx is done
+// while waiting.
+func (p Policy) Retry(ctx context.Context, attempt int, err error) bool {
+	if err == nil || attempt >= p.MaxAttempts || p.Retryable != nil && !p.Retryable(err) {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		return false
+	}
+	var wait time.Duration
+	if p.Backoff != nil {
+		wait = p.Backoff(attempt)
+	}
+	if wait <= 0 {
+		return true
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Exponential returns a Backoff that waits base after the first attempt,
+// and twice as long after each of the following ones, up to max.
+func Exponential(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		wait := base
+		for i := 1; i < attempt && wait < max; i++ {
+			wait *= 2
+		}
+		if wait > max {
+			return max
+		}
+		return wait
+	}
+}
@@ -0,0 +1,137 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ajmesa9891/impl/retry"
+)
+
+var _ Fetcher = &RetryFetcher{}
+
+var (
+	errTemporary = errors.New("temporary")
+	errPermanent = errors.New("permanent")
+)
+
+// flaky is a Fetcher whose calls fail with errs, in order, before they
+// succeed.
+type flaky struct {
+	errs  []error
+	calls int
+}
+
+func (f *flaky) fail() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *flaky) Fetch(ctx context.Context, url string) (Page, int, error) {
+	if err := f.fail(); err != nil {
+		return Page{Body: "partial"}, 206, err
+	}
+	return Page{Body: url}, 200, nil
+}
+
+func (f *flaky) Ping() error {
+	return f.fail()
+}
+
+func (f *flaky) Len() int {
+	f.calls++
+	return f.calls
+}
+
+func TestDecorator_RetriesUntilSuccess(t *testing.T) {
+	f := &flaky{errs: []error{errTemporary, errTemporary}}
+	r := &RetryFetcher{Inner: f, Policy: retry.Policy{MaxAttempts: 3}}
+	page, status, err := r.Fetch(context.Background(), "/roar")
+	if page.Body != "/roar" || status != 200 || err != nil {
+		t.Errorf("Fetch(ctx, %q) == %v, %d, %v, want {/roar}, 200, nil", "/roar", page, status, err)
+	}
+	if f.calls != 3 {
+		t.Errorf("Fetch(...) called the inner Fetcher %d times, want 3", f.calls)
+	}
+}
+
+func TestDecorator_ReturnsZeroValuesAndTheLastError(t *testing.T) {
+	cases := []struct {
+		name      string
+		errs      []error
+		policy    retry.Policy
+		wantErr   error
+		wantCalls int
+	}{
+		{"attempts exhausted", []error{errTemporary, errPermanent, errTemporary}, retry.Policy{MaxAttempts: 2}, errPermanent, 2},
+		{"zero policy", []error{errTemporary}, retry.Policy{}, errTemporary, 1},
+		{
+			"error not retryable",
+			[]error{errTemporary, errPermanent, errTemporary},
+			retry.Policy{MaxAttempts: 5, Retryable: func(err error) bool { return err == errTemporary }},
+			errPermanent,
+			2,
+		},
+	}
+	for _, c := range cases {
+		f := &flaky{errs: c.errs}
+		r := &RetryFetcher{Inner: f, Policy: c.policy}
+		page, status, err := r.Fetch(context.Background(), "/roar")
+		if page != (Page{}) || status != 0 || err != c.wantErr {
+			t.Errorf("%s: Fetch(...) == %v, %d, %v, want zero values and %v", c.name, page, status, err, c.wantErr)
+		}
+		if f.calls != c.wantCalls {
+			t.Errorf("%s: Fetch(...) called the inner Fetcher %d times, want %d", c.name, f.calls, c.wantCalls)
+		}
+	}
+}
+
+func TestDecorator_StopsWaitingWhenTheContextIsDone(t *testing.T) {
+	f := &flaky{errs: []error{errTemporary, errTemporary}}
+	r := &RetryFetcher{Inner: f, Policy: retry.Policy{MaxAttempts: 3, Backoff: func(int) time.Duration { return time.Hour }}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := r.Fetch(ctx, "/roar"); err != errTemporary {
+		t.Errorf("Fetch(...) == %v, want %v", err, errTemporary)
+	}
+	if f.calls != 1 {
+		t.Errorf("Fetch(...) called the inner Fetcher %d times, want 1", f.calls)
+	}
+}
+
+func TestDecorator_RetriesMethodsWithoutContexts(t *testing.T) {
+	f := &flaky{errs: []error{errTemporary}}
+	r := &RetryFetcher{Inner: f, Policy: retry.Policy{MaxAttempts: 2, Backoff: retry.Exponential(time.Millisecond, time.Millisecond)}}
+	if err := r.Ping(); err != nil || f.calls != 2 {
+		t.Errorf("Ping() == %v after %d calls, want nil after 2", err, f.calls)
+	}
+}
+
+func TestDecorator_ForwardsMethodsWithoutErrors(t *testing.T) {
+	f := &flaky{}
+	r := &RetryFetcher{Inner: f, Policy: retry.Policy{MaxAttempts: 3}}
+	if n := r.Len(); n != 1 {
+		t.Errorf("Len() == %d, want 1", n)
+	}
+}
+
+func TestExponential(t *testing.T) {
+	backoff := retry.Exponential(10*time.Millisecond, 50*time.Millisecond)
+	for attempt, want := range map[int]time.Duration{
+		1: 10 * time.Millisecond,
+		2: 20 * time.Millisecond,
+		3: 40 * time.Millisecond,
+		4: 50 * time.Millisecond,
+		9: 50 * time.Millisecond,
+	} {
+		if got := backoff(attempt); got != want {
+			t.Errorf("Exponential(10ms, 50ms)(%d) == %s, want %s", attempt, got, want)
+		}
+	}
+}